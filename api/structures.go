@@ -23,10 +23,12 @@ type Location struct {
 }
 
 type Date struct {
+	Id    int      `json:"id"`
 	Dates []string `json:"dates"`
 }
 
 type Relations struct {
+	Id            int                 `json:"id"`
 	DatesLocation map[string][]string `json:"datesLocations"`
 }
 
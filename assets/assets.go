@@ -0,0 +1,233 @@
+// Package assets serves the static files under content-hashed URLs, e.g. `/static/css/index.0123456789.css`,
+// which browsers may cache for good. The files are compressed with gzip once, at startup, and served in the
+// encoding the client accepts, brotli first, with the brotli variants precompressed next to them, e.g.
+// `searchbar.js.br`, see the go:generate directive of the main package.
+package assets
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"groupie-tracker/httperr"
+	"groupie-tracker/xhttp"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// hashLength is the number of hexadecimal digits of the content hash in the asset URLs
+	hashLength = 10
+	// immutable is the Cache-Control header of the content-hashed URLs, whose content never changes
+	immutable = "public, max-age=31536000, immutable"
+	// revalidate is the Cache-Control header of the plain URLs, which browsers must revalidate with the ETag
+	revalidate = "no-cache"
+)
+
+// compressible are the extensions of the files worth compressing. Images and woff2 fonts are compressed already.
+var compressible = map[string]bool{
+	".css": true, ".js": true, ".svg": true, ".html": true, ".json": true, ".txt": true, ".md": true, ".otf": true,
+}
+
+// cssURL matches the URLs of the static files in stylesheets, e.g. `url("/static/images/music.jpg")`
+var cssURL = regexp.MustCompile(`url\((['"]?)/static/([^'")]+)(['"]?)\)`)
+
+// encoding is a compressed variant of a file
+type encoding struct {
+	// name is the content coding, as in the Accept-Encoding and Content-Encoding headers
+	name    string
+	content []byte
+}
+
+// file is a static file, along with its compressed variants
+type file struct {
+	name string
+	// hashed is the name with the content hash before the extension, e.g. `css/index.0123456789.css`
+	hashed  string
+	hash    string
+	modTime time.Time
+	content []byte
+	// encodings are the compressed variants, preferred first
+	encodings []encoding
+}
+
+// Server serves the files of a file system under a URL prefix, e.g. `/static/`, at both their plain and
+// content-hashed paths. The plain paths must be revalidated by browsers, while the hashed paths are cached
+// for a year. Directories are not served.
+type Server struct {
+	fsys   fs.FS
+	prefix string
+	dev    bool
+	// files are the files by name and hashed name
+	files map[string]*file
+	// errors writes the error responses, as plain text if nil
+	errors *httperr.Writer
+}
+
+// New returns a server of the files of fsys under the URL prefix, reading, hashing and compressing them all at
+// once. In dev mode, the files are read again on every request instead, and Path returns their plain paths, so
+// that edits show on reload.
+func New(fsys fs.FS, prefix string, dev bool) (*Server, error) {
+	s := &Server{fsys: fsys, prefix: prefix, dev: dev, files: make(map[string]*file)}
+	if dev {
+		return s, nil
+	}
+
+	var names []string
+	err := fs.WalkDir(
+		fsys, ".", func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			// the brotli variants are served along with the files they compress
+			if d.Type().IsRegular() && path.Ext(name) != ".br" {
+				names = append(names, name)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// the stylesheets refer to the other files by their hashed paths, so they are hashed last
+	sort.SliceStable(
+		names, func(i, j int) bool {
+			return path.Ext(names[i]) != ".css" && path.Ext(names[j]) == ".css"
+		},
+	)
+	for _, name := range names {
+		f, err := s.load(name)
+		if err != nil {
+			return nil, err
+		}
+		s.files[f.name] = f
+		s.files[f.hashed] = f
+	}
+	return s, nil
+}
+
+// Path returns the URL path of the named file, e.g. `/static/css/index.0123456789.css` for `css/index.css`.
+// The plain path is returned in dev mode, or if there is no such file.
+func (s *Server) Path(name string) string {
+	if f, ok := s.files[name]; ok {
+		return s.prefix + f.hashed
+	}
+	return s.prefix + name
+}
+
+// WithErrors returns a copy of the server writing its error responses with errors, e.g. to render the error page
+// with the templates, which refer to the files by the paths of s
+func (s *Server) WithErrors(errors *httperr.Writer) *Server {
+	withErrors := *s
+	withErrors.errors = errors
+	return &withErrors
+}
+
+// ServeHTTP serves the file at the request's path, in the first encoding its Accept-Encoding header accepts,
+// brotli, then gzip. The Vary header is left to middleware.Gzip, which wraps every route.
+// It responds with 304 Not Modified if the request's If-None-Match header holds the file's ETag.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		s.errors.Write(w, r, httperr.HTML, httperr.New(http.StatusMethodNotAllowed, ""))
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, s.prefix)
+	f, ok := s.files[name]
+	if s.dev {
+		var err error
+		f, err = s.load(name)
+		ok = err == nil
+	}
+	if !ok {
+		s.errors.Write(w, r, httperr.HTML, httperr.New(http.StatusNotFound, ""))
+		return
+	}
+
+	header := w.Header()
+	if name == f.hashed && !s.dev {
+		header.Set("Cache-Control", immutable)
+	} else {
+		header.Set("Cache-Control", revalidate)
+	}
+	contentType := mime.TypeByExtension(path.Ext(f.name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header.Set("Content-Type", contentType)
+
+	content, etag := f.content, f.hash
+	for _, e := range f.encodings {
+		if xhttp.AcceptsEncoding(r.Header.Get("Accept-Encoding"), e.name) {
+			header.Set("Content-Encoding", e.name)
+			content, etag = e.content, f.hash+"-"+e.name
+			break
+		}
+	}
+	header.Set("ETag", strconv.Quote(etag))
+	http.ServeContent(w, r, f.name, f.modTime, bytes.NewReader(content))
+}
+
+// load reads the named file and its brotli variant, if any, hashing it and compressing it with gzip.
+// The URLs of the static files in stylesheets are replaced by their hashed paths.
+func (s *Server) load(name string) (*file, error) {
+	if !fs.ValidPath(name) {
+		return nil, fmt.Errorf("invalid file name %q", name)
+	}
+	info, err := fs.Stat(s.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a file", name)
+	}
+	content, err := fs.ReadFile(s.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if path.Ext(name) == ".css" {
+		content = cssURL.ReplaceAllFunc(
+			content, func(match []byte) []byte {
+				groups := cssURL.FindSubmatch(match)
+				return []byte(fmt.Sprintf("url(%s%s%s)", groups[1], s.Path(string(groups[2])), groups[3]))
+			},
+		)
+	}
+
+	sum := sha256.Sum256(content)
+	f := &file{name: name, hash: hex.EncodeToString(sum[:])[:hashLength], modTime: info.ModTime(), content: content}
+	ext := path.Ext(name)
+	f.hashed = strings.TrimSuffix(name, ext) + "." + f.hash + ext
+
+	// brotli variants can't be created with the standard library, so they are precompressed. They are left out for
+	// the stylesheets, whose content differs from the files they were compressed from, and in dev mode, where the
+	// files may have been edited since.
+	if br, err := fs.ReadFile(s.fsys, name+".br"); err == nil && ext != ".css" && !s.dev {
+		f.encodings = append(f.encodings, encoding{name: "br", content: br})
+	}
+	if compressible[ext] {
+		var buf bytes.Buffer
+		gz, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		if _, err := gz.Write(content); err != nil {
+			return nil, err
+		}
+		if err := gz.Close(); err != nil {
+			return nil, err
+		}
+		if buf.Len() < len(content) {
+			f.encodings = append(f.encodings, encoding{name: "gzip", content: buf.Bytes()})
+		}
+	}
+	return f, nil
+}
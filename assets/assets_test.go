@@ -0,0 +1,238 @@
+package assets
+
+import (
+	"bytes"
+	"compress/gzip"
+	"groupie-tracker/httperr"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// testFS returns static files: a stylesheet referring to an image, a script with a brotli variant, and the image
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"css/index.css":    {Data: []byte(`body { background: url("/static/images/music.jpg"); }` + strings.Repeat(" ", 200))},
+		"js/search.js":     {Data: []byte(strings.Repeat("console.log('search');\n", 20))},
+		"js/search.js.br":  {Data: []byte("brotli")},
+		"images/music.jpg": {Data: []byte("jpeg")},
+	}
+}
+
+func TestServerPath(t *testing.T) {
+	s, err := New(testFS(), "/static/", false)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	image := s.Path("images/music.jpg")
+	if !strings.HasPrefix(image, "/static/images/music.") || !strings.HasSuffix(image, ".jpg") ||
+		len(image) != len("/static/images/music.jpg")+hashLength+1 {
+		t.Errorf("Path() = %q, want the content-hashed path", image)
+	}
+	if got := s.Path("images/missing.png"); got != "/static/images/missing.png" {
+		t.Errorf("Path() = %q, want the plain path of a missing file", got)
+	}
+
+	// the stylesheet refers to the image by its hashed path
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, s.Path("css/index.css"), nil))
+	if !strings.Contains(w.Body.String(), `url("`+image+`")`) {
+		t.Errorf("stylesheet = %q, want the image URL replaced by %q", w.Body.String(), image)
+	}
+
+	dev, err := New(testFS(), "/static/", true)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got := dev.Path("images/music.jpg"); got != "/static/images/music.jpg" {
+		t.Errorf("Path() in dev mode = %q, want the plain path", got)
+	}
+}
+
+func TestServerServeHTTP(t *testing.T) {
+	s, err := New(testFS(), "/static/", false)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		acceptEncoding string
+		ifNoneMatch    string
+		wantStatus     int
+		wantEncoding   string
+		wantCache      string
+	}{
+		{
+			name: "Hashed path", method: http.MethodGet, path: s.Path("images/music.jpg"),
+			wantStatus: http.StatusOK, wantCache: immutable,
+		},
+		{
+			name: "Plain path", method: http.MethodGet, path: "/static/images/music.jpg",
+			wantStatus: http.StatusOK, wantCache: revalidate,
+		},
+		{
+			name: "Gzip", method: http.MethodGet, path: s.Path("css/index.css"), acceptEncoding: "gzip, deflate",
+			wantStatus: http.StatusOK, wantEncoding: "gzip", wantCache: immutable,
+		},
+		{
+			name: "Brotli preferred", method: http.MethodGet, path: s.Path("js/search.js"), acceptEncoding: "gzip, br",
+			wantStatus: http.StatusOK, wantEncoding: "br", wantCache: immutable,
+		},
+		{
+			name: "Brotli refused", method: http.MethodGet, path: s.Path("js/search.js"), acceptEncoding: "*, br;q=0",
+			wantStatus: http.StatusOK, wantEncoding: "gzip", wantCache: immutable,
+		},
+		{
+			name: "Compression refused", method: http.MethodGet, path: s.Path("js/search.js"),
+			acceptEncoding: "identity", wantStatus: http.StatusOK, wantCache: immutable,
+		},
+		{
+			name: "Not modified", method: http.MethodGet, path: "/static/images/music.jpg",
+			ifNoneMatch: `"` + s.files["images/music.jpg"].hash + `"`, wantStatus: http.StatusNotModified,
+			wantCache: revalidate,
+		},
+		{name: "Directory", method: http.MethodGet, path: "/static/css", wantStatus: http.StatusNotFound},
+		{name: "Root", method: http.MethodGet, path: "/static/", wantStatus: http.StatusNotFound},
+		{name: "Missing file", method: http.MethodGet, path: "/static/css/missing.css", wantStatus: http.StatusNotFound},
+		{
+			name: "Method not allowed", method: http.MethodPost, path: "/static/images/music.jpg",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				r := httptest.NewRequest(tt.method, tt.path, nil)
+				r.Header.Set("Accept", "text/plain")
+				if tt.acceptEncoding != "" {
+					r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+				}
+				if tt.ifNoneMatch != "" {
+					r.Header.Set("If-None-Match", tt.ifNoneMatch)
+				}
+				w := httptest.NewRecorder()
+				s.ServeHTTP(w, r)
+
+				if w.Code != tt.wantStatus {
+					t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+				}
+				if got := w.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+					t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+				}
+				if got := w.Header().Get("Cache-Control"); got != tt.wantCache {
+					t.Errorf("Cache-Control = %q, want %q", got, tt.wantCache)
+				}
+				if tt.wantStatus == http.StatusMethodNotAllowed && w.Header().Get("Allow") != "GET, HEAD" {
+					t.Errorf("Allow = %q, want %q", w.Header().Get("Allow"), "GET, HEAD")
+				}
+			},
+		)
+	}
+}
+
+func TestServerGzipContent(t *testing.T) {
+	fsys := testFS()
+	s, err := New(fsys, "/static/", false)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/static/js/search.js", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	body, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("reading the gzip body: %v", err)
+	}
+	if !bytes.Equal(body, fsys["js/search.js"].Data) {
+		t.Errorf("decompressed body = %q, want the file content", body)
+	}
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/javascript") {
+		t.Errorf("Content-Type = %q, want the type of the file", got)
+	}
+	if got := w.Header().Get("ETag"); got != `"`+s.files["js/search.js"].hash+`-gzip"` {
+		t.Errorf("ETag = %q, want the ETag of the gzip variant", got)
+	}
+}
+
+// errorPage renders every template as an error page
+type errorPage struct{}
+
+func (errorPage) Execute(w io.Writer, _ string, _ any) error {
+	_, err := io.WriteString(w, "<h1>error page</h1>")
+	return err
+}
+
+func TestServerWithErrors(t *testing.T) {
+	s, err := New(testFS(), "/static/", false)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	withErrors := s.WithErrors(httperr.NewWriter(errorPage{}))
+
+	tests := []struct {
+		name            string
+		server          *Server
+		wantContentType string
+	}{
+		{name: "Error page", server: withErrors, wantContentType: "text/html; charset=utf-8"},
+		{name: "Plain text", server: s, wantContentType: "text/plain; charset=utf-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				r := httptest.NewRequest(http.MethodGet, "/static/css/missing.css", nil)
+				r.Header.Set("Accept", "text/html")
+				w := httptest.NewRecorder()
+				tt.server.ServeHTTP(w, r)
+
+				if w.Code != http.StatusNotFound {
+					t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+				}
+				if got := w.Header().Get("Content-Type"); got != tt.wantContentType {
+					t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+				}
+			},
+		)
+	}
+
+	// the copy serves the same files
+	if got := withErrors.Path("images/music.jpg"); got != s.Path("images/music.jpg") {
+		t.Errorf("Path() = %q, want %q", got, s.Path("images/music.jpg"))
+	}
+}
+
+func TestServerBrotliInDevMode(t *testing.T) {
+	dev, err := New(testFS(), "/static/", true)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/static/js/search.js", nil)
+	r.Header.Set("Accept-Encoding", "br, gzip")
+	w := httptest.NewRecorder()
+	dev.ServeHTTP(w, r)
+
+	// the script may have been edited since its brotli variant was compressed
+	if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("Content-Encoding = %q, want %q", got, "gzip")
+	}
+	// the Vary header is set by middleware.Gzip
+	if got := w.Header().Values("Vary"); len(got) != 0 {
+		t.Errorf("Vary = %q, want none", got)
+	}
+}
@@ -3,48 +3,158 @@
 package cache
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"groupie-tracker/api"
+	"groupie-tracker/domain"
+	"groupie-tracker/location"
+	"groupie-tracker/stats"
+	"groupie-tracker/xerrors"
+	"log"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
-var (
-	artistCache      []api.Artist
-	locationCache    []api.Location
-	locationMapCache map[int][]string
-	dateCache        []api.Date
-	relationCache    []api.Relations
-	// cacheTime keeps track of when last the offline cache was updated with online content
-	cacheTime          time.Time
-	cacheMutex         sync.RWMutex
-	isCacheInitialized bool
-)
-
 // cacheDuration how long the application will work with offline
 // data before getting new data from the external API
 const cacheDuration = 2 * time.Hour
 
-// GetCachedData fetches artists data. If available locally, and the cache is still valid,
-// the data is returned immediately, else, network request is made to the Groupie Trackers API to get the latest data
-func GetCachedData() ([]api.Artist, []api.Location, []api.Date, []api.Relations, error) {
-	err := updateCache()
-	return artistCache, locationCache, dateCache, relationCache, err
+// versionLength is the number of hexadecimal digits of the data version
+const versionLength = 16
+
+// Source fetches the raw Groupie Trackers data
+type Source interface {
+	Artists() ([]api.Artist, error)
+	Locations() ([]api.Location, error)
+	Dates() ([]api.Date, error)
+	Relations() ([]api.Relations, error)
+}
+
+// APISource fetches the data from the Groupie Trackers API
+type APISource struct{}
+
+// Artists fetches the artists, see api.GetArtists
+func (APISource) Artists() ([]api.Artist, error) {
+	return api.GetArtists()
+}
+
+// Locations fetches the concert locations of all artists, see api.GetAllLocations
+func (APISource) Locations() ([]api.Location, error) {
+	return api.GetAllLocations()
+}
+
+// Dates fetches the concert dates of all artists, see api.GetAllDates
+func (APISource) Dates() ([]api.Date, error) {
+	return api.GetAllDates()
+}
+
+// Relations fetches the concert dates by location of all artists, see api.GetAllRelations
+func (APISource) Relations() ([]api.Relations, error) {
+	return api.GetAllRelations()
+}
+
+// Cache holds a local copy of the data of a source, fetching it again once it is older than cacheDuration
+type Cache struct {
+	source Source
+
+	mu          sync.RWMutex
+	artists     []api.Artist
+	locations   []api.Location
+	locationMap map[int][]string
+	dates       []api.Date
+	relations   []api.Relations
+	// snapshot holds the typed models parsed from the raw data
+	snapshot *domain.Snapshot
+	// stats holds the aggregate statistics computed from the snapshot
+	stats stats.Stats
+	// version identifies the content of the raw data, see Version
+	version string
+	// unknownLocations holds the concert location slugs that are missing from the offline location dictionary
+	unknownLocations []string
+	// fetchedAt keeps track of when last the cache was updated with the source's data, zero if never
+	fetchedAt time.Time
+}
+
+// New returns an empty cache of the source's data, which is fetched on first use
+func New(source Source) *Cache {
+	return &Cache{source: source}
+}
+
+// Data fetches artists data. If available locally, and the cache is still valid,
+// the data is returned immediately, else, the data is fetched again from the source
+func (c *Cache) Data() ([]api.Artist, []api.Location, []api.Date, []api.Relations, error) {
+	err := c.update()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.artists, c.locations, c.dates, c.relations, err
+}
+
+// Snapshot returns the typed artists data, parsed from the raw data when the cache was last refreshed.
+// The cache is refreshed first if it is no longer valid, see Data.
+func (c *Cache) Snapshot() (*domain.Snapshot, error) {
+	err := c.update()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.snapshot, err
+}
+
+// Stats returns the aggregate statistics of the artists, computed from the snapshot when the cache was last
+// refreshed. The cache is refreshed first if it is no longer valid, see Data.
+func (c *Cache) Stats() (stats.Stats, error) {
+	err := c.update()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.stats, err
+}
+
+// Version returns the version of the cached data: a hash of the raw data, which only changes when a cache
+// refresh fetches different data. The cache is refreshed first if it is no longer valid, see Data.
+func (c *Cache) Version() (string, error) {
+	err := c.update()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.version, err
 }
 
-// GetCachedLocationsMap returns the map cached locations data
-func GetCachedLocationsMap() map[int][]string {
-	return locationMapCache
+// LocationsMap returns the map cached locations data
+func (c *Cache) LocationsMap() map[int][]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.locationMap
 }
 
-func updateCache() error {
-	if isCacheInitialized && time.Since(cacheTime) < cacheDuration {
+// UnknownLocations returns the concert location slugs, found during the last cache refresh,
+// that could not be normalized with the offline location dictionary
+func (c *Cache) UnknownLocations() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.unknownLocations
+}
+
+// valid reports whether the cache holds data that is not older than cacheDuration
+func (c *Cache) valid() bool {
+	return !c.fetchedAt.IsZero() && time.Since(c.fetchedAt) < cacheDuration
+}
+
+func (c *Cache) update() error {
+	c.mu.RLock()
+	valid := c.valid()
+	c.mu.RUnlock()
+	if valid {
 		return nil
 	}
 
-	cacheMutex.Lock()
-	defer cacheMutex.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// another request may have refreshed the cache while this one waited for the lock
+	if c.valid() {
+		return nil
+	}
 
 	// keep track of how many errors have
 	//been encountered by the go routines
@@ -54,57 +164,88 @@ func updateCache() error {
 
 	go func() {
 		defer wg.Done()
-		artists, err := api.GetArtists()
+		artists, err := c.source.Artists()
 		if err != nil {
 			errCount.Add(1)
 			return
 		}
-		artistCache = artists
+		c.artists = artists
 	}()
 
 	go func() {
 		defer wg.Done()
-		locations, err := api.GetAllLocations()
+		locations, err := c.source.Locations()
 		if err != nil {
 			errCount.Add(1)
 			return
 		}
-		locationCache = locations
+		c.locations = locations
 	}()
 
 	go func() {
 		defer wg.Done()
-		dates, err := api.GetAllDates()
+		dates, err := c.source.Dates()
 		if err != nil {
 			errCount.Add(1)
 			return
 		}
-		dateCache = dates
+		c.dates = dates
 	}()
 
 	go func() {
 		defer wg.Done()
-		relations, err := api.GetAllRelations()
+		relations, err := c.source.Relations()
 		if err != nil {
 			errCount.Add(1)
 			return
 		}
-		relationCache = relations
+		c.relations = relations
 	}()
 
 	wg.Wait()
 	if errCount.Load() != 0 {
-		return fmt.Errorf("failed to fetch data from the Groupie Trackers API")
+		return fmt.Errorf("failed to fetch data from the Groupie Trackers API: %w", xerrors.ErrUnavailable)
 	}
 
-	cacheTime = time.Now()
-	isCacheInitialized = true
+	c.fetchedAt = time.Now()
 
 	// map the locations so that the keys are the id's of the artists
-	locationMapCache = make(map[int][]string)
-	for _, loc := range locationCache {
-		locationMapCache[loc.Id] = loc.Locations
+	c.locationMap = make(map[int][]string)
+	var slugs []string
+	for _, loc := range c.locations {
+		c.locationMap[loc.Id] = loc.Locations
+		slugs = append(slugs, loc.Locations...)
+	}
+
+	snapshot, err := domain.NewSnapshot(c.artists, c.locations, c.relations, c.fetchedAt)
+	if err != nil {
+		log.Printf("cache: some of the API data could not be parsed: %v\n", err)
+	}
+	c.snapshot = snapshot
+	c.stats = stats.New(snapshot)
+	c.version, err = version(c.artists, c.locations, c.dates, c.relations)
+	if err != nil {
+		log.Printf("cache: the data version could not be computed: %v\n", err)
+		c.version = strconv.FormatInt(c.fetchedAt.UnixNano(), 36)
+	}
+
+	// report the concert locations that can't be normalized, so that they can be added to the dictionary
+	c.unknownLocations = location.Unknown(slugs)
+	if len(c.unknownLocations) > 0 {
+		log.Printf(
+			"cache: %d concert location(s) missing from the location dictionary: %s\n",
+			len(c.unknownLocations), strings.Join(c.unknownLocations, ", "),
+		)
 	}
 
 	return nil
 }
+
+// version returns a hash of the JSON encoding of the data
+func version(data ...any) (string, error) {
+	hash := sha256.New()
+	if err := json.NewEncoder(hash).Encode(data); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:versionLength], nil
+}
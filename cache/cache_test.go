@@ -1,28 +1,24 @@
 package cache
 
 import (
+	"errors"
 	"groupie-tracker/api"
+	"groupie-tracker/xerrors"
 	"reflect"
 	"slices"
 	"testing"
 )
 
-func TestGetCachedDataIntegration(t *testing.T) {
-	// Invalidate all cache
-	artistCache = nil
-	locationCache = nil
-	locationMapCache = nil
-	dateCache = nil
-	relationCache = nil
-	isCacheInitialized = false
+func TestDataIntegration(t *testing.T) {
+	c := New(APISource{})
 
-	artists, locations, _, _, _ := GetCachedData()
+	artists, locations, _, _, _ := c.Data()
 	ValidateArtistsData(artists, t)
 
 	// Validate the integrity of the locations data
 	{
 		if locations == nil {
-			t.Fatalf("GetCachedData() locations is nil")
+			t.Fatalf("Data() locations is nil")
 		}
 
 		containsLocation := func(id int, locations []string) func(api.Location) bool {
@@ -92,7 +88,7 @@ func TestGetCachedDataIntegration(t *testing.T) {
 	}
 
 	// Fetch new data before cache expiration
-	artistsNew, _, _, _, _ := GetCachedData()
+	artistsNew, _, _, _, _ := c.Data()
 
 	// We expect the new data to also pass the artists data validation
 	ValidateArtistsData(artistsNew, t)
@@ -101,7 +97,7 @@ func TestGetCachedDataIntegration(t *testing.T) {
 // ValidateArtistsData validates the integrity of the given artists data
 func ValidateArtistsData(artists []api.Artist, t *testing.T) {
 	if artists == nil {
-		t.Fatalf("GetCachedData() artists is nil")
+		t.Fatalf("Data() artists is nil")
 	}
 
 	containsArtist := func(id int, name string) func(artist api.Artist) bool {
@@ -140,3 +136,90 @@ func ValidateArtistsData(artists []api.Artist, t *testing.T) {
 	}
 
 }
+
+// fakeSource serves fixed data, or fails if err is set
+type fakeSource struct {
+	artists []api.Artist
+	err     error
+}
+
+func (s fakeSource) Artists() ([]api.Artist, error) {
+	return s.artists, s.err
+}
+
+func (s fakeSource) Locations() ([]api.Location, error) {
+	return []api.Location{{Id: 1, Locations: []string{"london-uk"}}}, s.err
+}
+
+func (s fakeSource) Dates() ([]api.Date, error) {
+	return []api.Date{{Id: 1, Dates: []string{"*01-01-1980"}}}, s.err
+}
+
+func (s fakeSource) Relations() ([]api.Relations, error) {
+	return []api.Relations{{Id: 1, DatesLocation: map[string][]string{"london-uk": {"01-01-1980"}}}}, s.err
+}
+
+func TestCache(t *testing.T) {
+	queen := []api.Artist{{ID: 1, Name: "Queen", FirstAlbum: "14-12-1973"}}
+	c := New(fakeSource{artists: queen})
+
+	snapshot, err := c.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if artist, ok := snapshot.Artist(1); !ok || artist.Name != "Queen" || len(artist.Concerts) != 1 {
+		t.Errorf("Snapshot() artist 1 = %+v, want Queen with a concert", artist)
+	}
+	if s, _ := c.Stats(); s.ArtistCount != 1 || s.ConcertCount != 1 {
+		t.Errorf("Stats() = %+v, want 1 artist and 1 concert", s)
+	}
+	if v, _ := c.Version(); len(v) != versionLength {
+		t.Errorf("Version() = %q, want %d hexadecimal digits", v, versionLength)
+	}
+
+	// another cache of other data is independent
+	other := New(fakeSource{artists: []api.Artist{{ID: 1, Name: "Gorillaz"}}})
+	if snapshot, _ := other.Snapshot(); snapshot.Artists[0].Name != "Gorillaz" {
+		t.Errorf("Snapshot() of another cache = %+v, want Gorillaz", snapshot.Artists)
+	}
+	if v1, v2 := mustVersion(t, c), mustVersion(t, other); v1 == v2 {
+		t.Errorf("Version() = %q for both caches, want the versions of different data to differ", v1)
+	}
+
+	failing := New(fakeSource{err: errors.New("offline")})
+	if _, err := failing.Snapshot(); !errors.Is(err, xerrors.ErrUnavailable) {
+		t.Errorf("Snapshot() error = %v, want ErrUnavailable", err)
+	}
+}
+
+// mustVersion returns the version of the cache's data
+func mustVersion(t *testing.T, c *Cache) string {
+	t.Helper()
+	v, err := c.Version()
+	if err != nil {
+		t.Fatalf("Version() error = %v", err)
+	}
+	return v
+}
+
+func TestVersion(t *testing.T) {
+	artists := []api.Artist{{ID: 1, Name: "Queen"}}
+	changed := []api.Artist{{ID: 1, Name: "Queen II"}}
+
+	v1, err := version(artists, []api.Location{})
+	if err != nil {
+		t.Fatalf("version() error = %v", err)
+	}
+	v2, _ := version(artists, []api.Location{})
+	v3, _ := version(changed, []api.Location{})
+
+	if len(v1) != versionLength {
+		t.Errorf("version() = %q, want %d hexadecimal digits", v1, versionLength)
+	}
+	if v1 != v2 {
+		t.Errorf("version() = %q then %q, want the same version for the same data", v1, v2)
+	}
+	if v1 == v3 {
+		t.Errorf("version() = %q, want another version for different data", v3)
+	}
+}
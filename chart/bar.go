@@ -0,0 +1,43 @@
+package chart
+
+import (
+	"html/template"
+)
+
+// Bar is a labelled value of a bar chart
+type Bar struct {
+	Label string
+	Value float64
+}
+
+// Bars renders a horizontal bar chart of the bars, in the order given. The longest bar is the largest value,
+// the values are written right of the bars.
+func Bars(title string, bars []Bar) template.HTML {
+	// leave room for the values right of the longest bar
+	maxWidth := float64(Width - labelWidth - 50)
+
+	largest := 0.0
+	for _, bar := range bars {
+		if bar.Value > largest {
+			largest = bar.Value
+		}
+	}
+
+	s := newSVG("chart-bars", title, len(bars)*rowHeight)
+	for i, bar := range bars {
+		y := float64(i * rowHeight)
+		width := 0.0
+		if largest > 0 && bar.Value > 0 {
+			width = bar.Value / largest * maxWidth
+		}
+
+		s.printf(`<g class="chart-bar"><title>%s: %s</title>`, escape(bar.Label), formatTick(bar.Value))
+		s.text("chart-label chart-label-row", labelWidth-8, y+15, truncate(bar.Label))
+		s.printf(
+			`<rect height="16" width="%s" x="%d" y="%s"></rect>`, num(width), labelWidth, num(y+2),
+		)
+		s.text("chart-value", labelWidth+width+6, y+15, formatTick(bar.Value))
+		s.printf(`</g>`)
+	}
+	return s.html()
+}
@@ -0,0 +1,123 @@
+// Package chart renders bar charts, histograms, timelines and scatter plots as inline SVG, so that pages can show
+// charts without any JavaScript chart library
+package chart
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// Width of the charts in SVG user units. The charts scale to the width of their container.
+	Width = 560
+
+	// labelWidth is the width of the row labels, left of the bars and timelines
+	labelWidth = 160
+	// maxLabelLength is the number of characters row labels are truncated to
+	maxLabelLength = 24
+	// rowHeight is the height of a row of the bar charts and timelines
+	rowHeight = 22
+	// axisHeight is the height of the horizontal axis, below the plot area
+	axisHeight = 24
+	// axisWidth is the width of the vertical axis, left of the plot area
+	axisWidth = 40
+	// plotHeight is the height of the plot area of the histograms and scatter plots
+	plotHeight = 180
+	// margin keeps the axis labels at the edges of the plot area within the chart
+	margin = 12
+)
+
+// FuncMap returns the template functions rendering the charts: barChart, histogram, timeline and scatterPlot.
+// It can be passed to the Funcs method of both text/template and html/template templates.
+func FuncMap() map[string]any {
+	return map[string]any{
+		"barChart":    Bars,
+		"histogram":   Histogram,
+		"timeline":    Timeline,
+		"scatterPlot": Scatter,
+	}
+}
+
+// svg builds the markup of a chart
+type svg struct {
+	b strings.Builder
+}
+
+// newSVG starts a chart of the given height, labelled with the title for assistive technologies
+func newSVG(class, title string, height int) *svg {
+	s := &svg{}
+	s.printf(
+		`<svg aria-label="%s" class="chart %s" role="img" viewBox="0 0 %d %d" xmlns="http://www.w3.org/2000/svg">`,
+		escape(title), class, Width, height,
+	)
+	return s
+}
+
+// printf appends formatted markup to the chart. String arguments are not escaped.
+func (s *svg) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(&s.b, format, args...)
+}
+
+// text appends a text element, escaping its content
+func (s *svg) text(class string, x, y float64, content string) {
+	s.printf(`<text class="%s" x="%s" y="%s">%s</text>`, class, num(x), num(y), escape(content))
+}
+
+// html closes the chart and returns its markup
+func (s *svg) html() template.HTML {
+	s.b.WriteString("</svg>")
+	return template.HTML(s.b.String())
+}
+
+// escape escapes the text for use in SVG markup
+func escape(text string) string {
+	return template.HTMLEscapeString(text)
+}
+
+// num formats the coordinate with at most one decimal
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64)
+}
+
+// truncate shortens the label to maxLabelLength characters, ending it with an ellipsis if it is shortened
+func truncate(label string) string {
+	if utf8.RuneCountInString(label) <= maxLabelLength {
+		return label
+	}
+	runes := []rune(label)
+	return string(runes[:maxLabelLength-1]) + "…"
+}
+
+// ticks returns at most about n evenly spaced round values covering the range from lo to hi, e.g. 0, 5, 10
+func ticks(lo, hi float64, n int) []float64 {
+	if hi <= lo {
+		return []float64{lo}
+	}
+
+	step := niceStep((hi - lo) / float64(n))
+	var result []float64
+	for v := math.Floor(lo/step) * step; v <= hi+step/2; v += step {
+		result = append(result, math.Round(v/step)*step)
+	}
+	return result
+}
+
+// niceStep rounds the step up to 1, 2 or 5 times a power of ten
+func niceStep(step float64) float64 {
+	magnitude := math.Pow(10, math.Floor(math.Log10(step)))
+	for _, nice := range []float64{1, 2, 5} {
+		if step <= nice*magnitude {
+			return nice * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// formatTick formats an axis value, without trailing zeros
+func formatTick(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
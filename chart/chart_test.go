@@ -0,0 +1,169 @@
+package chart
+
+import (
+	"encoding/xml"
+	"html/template"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wellFormed fails the test if the chart is not well-formed XML
+func wellFormed(t *testing.T, chart template.HTML) {
+	t.Helper()
+	decoder := xml.NewDecoder(strings.NewReader(string(chart)))
+	for {
+		_, err := decoder.Token()
+		if err != nil {
+			if err.Error() != "EOF" {
+				t.Errorf("chart is not well-formed: %v\n%s", err, chart)
+			}
+			return
+		}
+	}
+}
+
+func TestTicks(t *testing.T) {
+	tests := []struct {
+		name string
+		lo   float64
+		hi   float64
+		n    int
+		want []float64
+	}{
+		{name: "Counts", lo: 0, hi: 7, n: 4, want: []float64{0, 2, 4, 6, 8}},
+		{name: "Years", lo: 1958, hi: 2015, n: 6, want: []float64{1950, 1960, 1970, 1980, 1990, 2000, 2010, 2020}},
+		{name: "Fractions", lo: 0, hi: 1, n: 4, want: []float64{0, 0.5, 1}},
+		{name: "Single value", lo: 3, hi: 3, n: 4, want: []float64{3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := ticks(tt.lo, tt.hi, tt.n); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("ticks() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestBins(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		width  float64
+		want   []Bin
+	}{
+		{
+			name:   "Contiguous with empty bins",
+			values: []float64{1, 1.5, 4},
+			width:  1,
+			want:   []Bin{{1, 2, 2}, {2, 3, 0}, {3, 4, 0}, {4, 5, 1}},
+		},
+		{
+			name:   "Aligned on the width",
+			values: []float64{-3, 7},
+			width:  5,
+			want:   []Bin{{-5, 0, 1}, {0, 5, 0}, {5, 10, 1}},
+		},
+		{
+			name:   "No values",
+			values: nil,
+			width:  1,
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := Bins(tt.values, tt.width); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Bins() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("London"); got != "London" {
+		t.Errorf("truncate() = %q, want London", got)
+	}
+	got := truncate("Los Angeles, California, United States")
+	if len([]rune(got)) != maxLabelLength || !strings.HasSuffix(got, "…") {
+		t.Errorf("truncate() = %q, want %d characters ending with an ellipsis", got, maxLabelLength)
+	}
+}
+
+func TestCharts(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		chart template.HTML
+		want  []string
+	}{
+		{
+			name:  "Bars",
+			chart: Bars("Cities", []Bar{{Label: "<London>", Value: 4}, {Label: "Paris", Value: 1}}),
+			want: []string{
+				`aria-label="Cities"`,
+				`viewBox="0 0 560 44"`,
+				`<title>&lt;London&gt;: 4</title>`,
+				`width="350" x="160"`,
+				`width="87.5" x="160"`,
+			},
+		},
+		{
+			name:  "Histogram",
+			chart: Histogram("Delays", Bins([]float64{0, 1, 1, 3}, 1)),
+			want:  []string{`class="chart chart-histogram"`, `<title>1–2: 2</title>`, `<title>2–3: 0</title>`},
+		},
+		{
+			name: "Timeline",
+			chart: Timeline(
+				"Concerts", []TimelineRow{
+					{Label: "France", Dates: []time.Time{date(2019, time.July, 2)}},
+					{Label: "Japan", Dates: []time.Time{date(2020, time.January, 1)}},
+				},
+			),
+			want: []string{
+				`viewBox="0 0 560 68"`,
+				`<title>France: 2019-07-02</title>`,
+				`>2019</text>`,
+				`>2021</text>`,
+				`cx="353.7" cy="33"`,
+			},
+		},
+		{
+			name: "Scatter",
+			chart: Scatter(
+				"Careers", []Point{{Label: "Queen", X: 1970, Y: 1973}, {Label: "Coldplay", X: 1996, Y: 2000}},
+				"creation", "first album",
+			),
+			want: []string{`<title>Queen (1970, 1973)</title>`, `>creation</text>`, `>first album</text>`},
+		},
+		{
+			name:  "Empty timeline",
+			chart: Timeline("Concerts", nil),
+			want:  []string{`viewBox="0 0 560 24"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				wellFormed(t, tt.chart)
+				for _, want := range tt.want {
+					if !strings.Contains(string(tt.chart), want) {
+						t.Errorf("chart does not contain %s:\n%s", want, tt.chart)
+					}
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,97 @@
+package chart
+
+import (
+	"html/template"
+	"math"
+)
+
+// Bin is a bucket of a histogram, counting the values from Start up to, but not including, End
+type Bin struct {
+	Start float64
+	End   float64
+	Count int
+}
+
+// Bins buckets the values into contiguous bins of the given width, aligned on multiples of the width.
+// The bins between the smallest and largest values are all returned, even if empty.
+func Bins(values []float64, width float64) []Bin {
+	if len(values) == 0 || width <= 0 {
+		return nil
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+
+	first := math.Floor(lo / width)
+	bins := make([]Bin, int(math.Floor(hi/width)-first)+1)
+	for i := range bins {
+		bins[i].Start = (first + float64(i)) * width
+		bins[i].End = bins[i].Start + width
+	}
+	for _, v := range values {
+		bins[int(math.Floor(v/width)-first)].Count++
+	}
+	return bins
+}
+
+// Histogram renders the bins as adjacent columns, with the count axis on the left and the bin edges below
+func Histogram(title string, bins []Bin) template.HTML {
+	plotWidth := float64(Width - axisWidth - margin)
+	height := plotHeight + axisHeight
+
+	s := newSVG("chart-histogram", title, height)
+	if len(bins) == 0 {
+		return s.html()
+	}
+
+	largest := 0
+	for _, bin := range bins {
+		if bin.Count > largest {
+			largest = bin.Count
+		}
+	}
+
+	// count axis, with horizontal grid lines
+	counts := ticks(0, float64(largest), 4)
+	top := counts[len(counts)-1]
+	y := func(count float64) float64 {
+		if top == 0 {
+			return plotHeight
+		}
+		return plotHeight - count/top*(plotHeight-margin)
+	}
+	for _, count := range counts {
+		s.printf(
+			`<line class="chart-grid" x1="%d" x2="%d" y1="%s" y2="%s"></line>`,
+			axisWidth, Width-margin, num(y(count)), num(y(count)),
+		)
+		s.text("chart-label chart-label-axis-y", axisWidth-6, y(count)+4, formatTick(count))
+	}
+
+	// label every n-th bin edge, so that the labels don't overlap
+	columnWidth := plotWidth / float64(len(bins))
+	every := int(math.Ceil(36 / columnWidth))
+	for i, bin := range bins {
+		x := axisWidth + float64(i)*columnWidth
+		s.printf(
+			`<g class="chart-bar"><title>%s–%s: %d</title>`, formatTick(bin.Start), formatTick(bin.End), bin.Count,
+		)
+		s.printf(
+			`<rect height="%s" width="%s" x="%s" y="%s"></rect></g>`,
+			num(plotHeight-y(float64(bin.Count))), num(math.Max(columnWidth-1, 1)), num(x), num(y(float64(bin.Count))),
+		)
+		if i%every == 0 {
+			s.text("chart-label chart-label-axis-x", x, plotHeight+16, formatTick(bin.Start))
+		}
+	}
+	if len(bins)%every == 0 {
+		s.text("chart-label chart-label-axis-x", axisWidth+plotWidth, plotHeight+16, formatTick(bins[len(bins)-1].End))
+	}
+	s.printf(
+		`<line class="chart-axis" x1="%d" x2="%d" y1="%d" y2="%d"></line>`,
+		axisWidth, Width-margin, plotHeight, plotHeight,
+	)
+	return s.html()
+}
@@ -0,0 +1,79 @@
+package chart
+
+import (
+	"html/template"
+)
+
+// Point is a labelled point of a scatter plot
+type Point struct {
+	Label string
+	X     float64
+	Y     float64
+}
+
+// Scatter renders the points on axes spanning round values around them, with the axes named by xLabel and yLabel
+func Scatter(title string, points []Point, xLabel, yLabel string) template.HTML {
+	// leave room below the x axis for its name
+	height := plotHeight + axisHeight + 16
+	s := newSVG("chart-scatter", title, height)
+	if len(points) == 0 {
+		return s.html()
+	}
+
+	minX, maxX, minY, maxY := points[0].X, points[0].X, points[0].Y, points[0].Y
+	for _, p := range points {
+		if p.X < minX {
+			minX = p.X
+		}
+		if p.X > maxX {
+			maxX = p.X
+		}
+		if p.Y < minY {
+			minY = p.Y
+		}
+		if p.Y > maxY {
+			maxY = p.Y
+		}
+	}
+
+	xTicks, yTicks := ticks(minX, maxX, 6), ticks(minY, maxY, 4)
+	xLo, xHi := xTicks[0], xTicks[len(xTicks)-1]
+	yLo, yHi := yTicks[0], yTicks[len(yTicks)-1]
+	plotWidth := float64(Width - axisWidth - 2*margin)
+	x := func(v float64) float64 {
+		if xHi == xLo {
+			return axisWidth + margin + plotWidth/2
+		}
+		return axisWidth + margin + (v-xLo)/(xHi-xLo)*plotWidth
+	}
+	y := func(v float64) float64 {
+		if yHi == yLo {
+			return plotHeight / 2
+		}
+		return plotHeight - (v-yLo)/(yHi-yLo)*(plotHeight-2*margin)
+	}
+
+	for _, tick := range yTicks {
+		s.printf(
+			`<line class="chart-grid" x1="%d" x2="%d" y1="%s" y2="%s"></line>`,
+			axisWidth, Width-margin, num(y(tick)), num(y(tick)),
+		)
+		s.text("chart-label chart-label-axis-y", axisWidth-6, y(tick)+4, formatTick(tick))
+	}
+	for _, tick := range xTicks {
+		s.printf(
+			`<line class="chart-grid" x1="%s" x2="%s" y1="0" y2="%d"></line>`, num(x(tick)), num(x(tick)), plotHeight,
+		)
+		s.text("chart-label chart-label-axis-x", x(tick), plotHeight+16, formatTick(tick))
+	}
+	s.text("chart-label chart-label-axis-name", Width-margin, float64(height-4), xLabel)
+	s.text("chart-label chart-label-axis-name chart-label-axis-name-y", axisWidth+4, margin-2, yLabel)
+
+	for _, p := range points {
+		s.printf(
+			`<circle class="chart-point" cx="%s" cy="%s" r="4"><title>%s (%s, %s)</title></circle>`,
+			num(x(p.X)), num(y(p.Y)), escape(p.Label), formatTick(p.X), formatTick(p.Y),
+		)
+	}
+	return s.html()
+}
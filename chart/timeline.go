@@ -0,0 +1,66 @@
+package chart
+
+import (
+	"html/template"
+	"math"
+	"time"
+)
+
+// TimelineRow is a labelled row of dates of a timeline
+type TimelineRow struct {
+	Label string
+	Dates []time.Time
+}
+
+// Timeline renders a row of points per row, placed on a shared time axis spanning whole years
+func Timeline(title string, rows []TimelineRow) template.HTML {
+	var first, last time.Time
+	for _, row := range rows {
+		for _, date := range row.Dates {
+			if first.IsZero() || date.Before(first) {
+				first = date
+			}
+			if last.IsZero() || date.After(last) {
+				last = date
+			}
+		}
+	}
+
+	plotBottom := len(rows) * rowHeight
+	s := newSVG("chart-timeline", title, plotBottom+axisHeight)
+	if first.IsZero() {
+		return s.html()
+	}
+
+	start := time.Date(first.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(last.Year()+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	plotWidth := float64(Width - labelWidth - margin)
+	x := func(date time.Time) float64 {
+		return labelWidth + float64(date.Sub(start))/float64(end.Sub(start))*plotWidth
+	}
+
+	// year axis, with vertical grid lines
+	step := int(math.Max(1, niceStep(float64(end.Year()-start.Year())/6)))
+	for year := start.Year(); year <= end.Year(); year += step {
+		tick := x(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC))
+		s.printf(`<line class="chart-grid" x1="%s" x2="%s" y1="0" y2="%d"></line>`, num(tick), num(tick), plotBottom)
+		s.text("chart-label chart-label-axis-x", tick, float64(plotBottom+16), formatTick(float64(year)))
+	}
+
+	for i, row := range rows {
+		y := float64(i*rowHeight) + rowHeight/2
+		s.printf(`<g class="chart-row"><title>%s</title>`, escape(row.Label))
+		s.text("chart-label chart-label-row", labelWidth-8, y+4, truncate(row.Label))
+		s.printf(
+			`<line class="chart-axis" x1="%d" x2="%d" y1="%s" y2="%s"></line></g>`,
+			labelWidth, Width-margin, num(y), num(y),
+		)
+		for _, date := range row.Dates {
+			s.printf(
+				`<circle class="chart-point" cx="%s" cy="%s" r="4"><title>%s: %s</title></circle>`,
+				num(x(date)), num(y), escape(row.Label), date.Format("2006-01-02"),
+			)
+		}
+	}
+	return s.html()
+}
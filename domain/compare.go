@@ -0,0 +1,211 @@
+package domain
+
+import (
+	"encoding/json"
+	"groupie-tracker/location"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ArtistSummary holds the figures compared between artists
+type ArtistSummary struct {
+	Artist       Artist
+	MemberCount  int
+	ConcertCount int
+	// Countries are the names of the distinct countries the artist played in, in alphabetical order
+	Countries []string
+}
+
+// MarshalJSON encodes the summary, with the artist's fields inlined and without its concerts
+func (s ArtistSummary) MarshalJSON() ([]byte, error) {
+	firstAlbum := ""
+	if !s.Artist.FirstAlbum.IsZero() {
+		firstAlbum = s.Artist.FirstAlbum.Format(dateLayout)
+	}
+
+	return json.Marshal(
+		struct {
+			ID           int      `json:"id"`
+			Image        string   `json:"image"`
+			Name         string   `json:"name"`
+			CreationDate int      `json:"creationDate"`
+			FirstAlbum   string   `json:"firstAlbum"`
+			MemberCount  int      `json:"memberCount"`
+			ConcertCount int      `json:"concertCount"`
+			Countries    []string `json:"countries"`
+		}{
+			ID:           s.Artist.ID,
+			Image:        s.Artist.Image,
+			Name:         s.Artist.Name,
+			CreationDate: s.Artist.CreationDate,
+			FirstAlbum:   firstAlbum,
+			MemberCount:  s.MemberCount,
+			ConcertCount: s.ConcertCount,
+			Countries:    s.Countries,
+		},
+	)
+}
+
+// SharedPlace is a place where at least two of the compared artists played
+type SharedPlace struct {
+	Place location.Place
+	// Dates holds each artist's concert dates at the place, in the order of Comparison.Artists.
+	// The dates of an artist who never played at the place are nil.
+	Dates [][]time.Time
+	// SameDates are the dates on which at least two of the artists played at the place, in chronological order
+	SameDates []time.Time
+}
+
+// SameDate reports whether at least two of the artists played at the place on the date
+func (p SharedPlace) SameDate(date time.Time) bool {
+	for _, same := range p.SameDates {
+		if same.Equal(date) {
+			return true
+		}
+	}
+	return false
+}
+
+// Comparison holds artists side by side
+type Comparison struct {
+	Artists []ArtistSummary
+	// SharedPlaces are ordered by the number of artists who played there, then by name
+	SharedPlaces []SharedPlace
+}
+
+// MarshalJSON encodes the comparison, with each shared place's dates keyed by artist ID
+func (c Comparison) MarshalJSON() ([]byte, error) {
+	type sharedPlace struct {
+		Place     location.Place      `json:"place"`
+		Dates     map[string][]string `json:"dates"`
+		SameDates []string            `json:"sameDates"`
+	}
+
+	shared := make([]sharedPlace, 0, len(c.SharedPlaces))
+	for _, p := range c.SharedPlaces {
+		sp := sharedPlace{Place: p.Place, Dates: make(map[string][]string), SameDates: formatDates(p.SameDates)}
+		for i, dates := range p.Dates {
+			if len(dates) > 0 {
+				sp.Dates[strconv.Itoa(c.Artists[i].Artist.ID)] = formatDates(dates)
+			}
+		}
+		shared = append(shared, sp)
+	}
+
+	artists := c.Artists
+	if artists == nil {
+		artists = []ArtistSummary{}
+	}
+	return json.Marshal(
+		struct {
+			Artists      []ArtistSummary `json:"artists"`
+			SharedPlaces []sharedPlace   `json:"sharedPlaces"`
+		}{Artists: artists, SharedPlaces: shared},
+	)
+}
+
+// Compare returns the summaries of the artists, in the given order, and the places where at least two of them played
+func Compare(artists []Artist) Comparison {
+	comparison := Comparison{Artists: make([]ArtistSummary, 0, len(artists))}
+
+	index := make(map[string]int)
+	for i, artist := range artists {
+		countries := make(map[string]bool)
+		for _, concert := range artist.Concerts {
+			countries[concert.Place.Country] = true
+		}
+		summary := ArtistSummary{
+			Artist:       artist,
+			MemberCount:  len(artist.Members),
+			ConcertCount: len(artist.Concerts),
+			Countries:    make([]string, 0, len(countries)),
+		}
+		for country := range countries {
+			summary.Countries = append(summary.Countries, country)
+		}
+		sort.Strings(summary.Countries)
+		comparison.Artists = append(comparison.Artists, summary)
+
+		for _, group := range artist.ConcertsByPlace() {
+			j, ok := index[group.Place.Slug]
+			if !ok {
+				j = len(comparison.SharedPlaces)
+				index[group.Place.Slug] = j
+				comparison.SharedPlaces = append(
+					comparison.SharedPlaces,
+					SharedPlace{Place: group.Place, Dates: make([][]time.Time, len(artists))},
+				)
+			}
+			comparison.SharedPlaces[j].Dates[i] = group.Dates
+		}
+	}
+
+	// keep the places played by at least two artists
+	shared := comparison.SharedPlaces[:0]
+	for _, place := range comparison.SharedPlaces {
+		if artistCount(place) >= 2 {
+			place.SameDates = sameDates(place.Dates)
+			shared = append(shared, place)
+		}
+	}
+	comparison.SharedPlaces = shared
+
+	sort.SliceStable(
+		comparison.SharedPlaces, func(i, j int) bool {
+			a, b := comparison.SharedPlaces[i], comparison.SharedPlaces[j]
+			if n, m := artistCount(a), artistCount(b); n != m {
+				return n > m
+			}
+			return a.Place.String() < b.Place.String()
+		},
+	)
+	return comparison
+}
+
+// artistCount returns the number of artists who played at the place
+func artistCount(place SharedPlace) int {
+	count := 0
+	for _, dates := range place.Dates {
+		if len(dates) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// sameDates returns the dates found in at least two of the lists, in chronological order
+func sameDates(lists [][]time.Time) []time.Time {
+	counts := make(map[time.Time]int)
+	for _, dates := range lists {
+		seen := make(map[time.Time]bool, len(dates))
+		for _, date := range dates {
+			if !seen[date] {
+				seen[date] = true
+				counts[date]++
+			}
+		}
+	}
+
+	var same []time.Time
+	for date, count := range counts {
+		if count >= 2 {
+			same = append(same, date)
+		}
+	}
+	sort.Slice(
+		same, func(i, j int) bool {
+			return same[i].Before(same[j])
+		},
+	)
+	return same
+}
+
+// formatDates returns the dates in the YYYY-MM-DD format
+func formatDates(dates []time.Time) []string {
+	formatted := make([]string, 0, len(dates))
+	for _, date := range dates {
+		formatted = append(formatted, date.Format(dateLayout))
+	}
+	return formatted
+}
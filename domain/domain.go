@@ -0,0 +1,167 @@
+// Package domain holds the typed models of the Groupie Trackers data. The raw API payloads, with dates and
+// locations as strings, are converted into these models once per cache refresh.
+package domain
+
+import (
+	"encoding/json"
+	"groupie-tracker/api"
+	"groupie-tracker/location"
+	"sort"
+	"strings"
+	"time"
+)
+
+// dateLayout is the layout dates are encoded in, in JSON
+const dateLayout = "2006-01-02"
+
+// Concert is a single show, held at a place on a date
+type Concert struct {
+	Place location.Place
+	Date  time.Time
+}
+
+// MarshalJSON encodes the concert, with the date in the YYYY-MM-DD format
+func (c Concert) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			Place location.Place `json:"place"`
+			Date  string         `json:"date"`
+		}{Place: c.Place, Date: c.Date.Format(dateLayout)},
+	)
+}
+
+// PlaceConcerts holds the dates of the concerts held at a place
+type PlaceConcerts struct {
+	Place location.Place
+	// Dates are in chronological order
+	Dates []time.Time
+}
+
+// Artist is an artist or band, with its concerts
+type Artist struct {
+	ID           int
+	Image        string
+	Name         string
+	Members      []string
+	CreationDate int
+	// FirstAlbum is the release date of the first album, the zero time if unknown
+	FirstAlbum time.Time
+	// Locations are the distinct concert locations, in the order listed by the API
+	Locations []location.Place
+	// Concerts are in chronological order
+	Concerts []Concert
+	// Raw is the artist as sent by the Groupie Trackers API, with its first album date as a string and the URLs of
+	// its locations, dates and relations
+	Raw api.Artist
+}
+
+// Slug returns the artist's name in lower case, with the runs of characters other than ASCII letters and digits
+// replaced by hyphens, e.g. `guns-n-roses` for `Guns N' Roses`. It is empty if the name has no such letters or digits.
+func (a Artist) Slug() string {
+	var slug strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(a.Name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if hyphen && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			slug.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	return slug.String()
+}
+
+// MarshalJSON encodes the artist with the same field names as the Groupie Trackers API, along with its slug,
+// with the dates in the YYYY-MM-DD format
+func (a Artist) MarshalJSON() ([]byte, error) {
+	firstAlbum := ""
+	if !a.FirstAlbum.IsZero() {
+		firstAlbum = a.FirstAlbum.Format(dateLayout)
+	}
+
+	return json.Marshal(
+		struct {
+			ID           int              `json:"id"`
+			Slug         string           `json:"slug"`
+			Image        string           `json:"image"`
+			Name         string           `json:"name"`
+			Members      []string         `json:"members"`
+			CreationDate int              `json:"creationDate"`
+			FirstAlbum   string           `json:"firstAlbum"`
+			Locations    []location.Place `json:"locations"`
+			Concerts     []Concert        `json:"concerts"`
+		}{
+			ID:           a.ID,
+			Slug:         a.Slug(),
+			Image:        a.Image,
+			Name:         a.Name,
+			Members:      a.Members,
+			CreationDate: a.CreationDate,
+			FirstAlbum:   firstAlbum,
+			Locations:    a.Locations,
+			Concerts:     a.Concerts,
+		},
+	)
+}
+
+// ConcertsByPlace groups the artist's concerts by place, in the order of the artist's locations
+func (a Artist) ConcertsByPlace() []PlaceConcerts {
+	index := make(map[string]int, len(a.Locations))
+	groups := make([]PlaceConcerts, 0, len(a.Locations))
+	for _, place := range a.Locations {
+		if _, ok := index[place.Slug]; !ok {
+			index[place.Slug] = len(groups)
+			groups = append(groups, PlaceConcerts{Place: place})
+		}
+	}
+
+	for _, concert := range a.Concerts {
+		i, ok := index[concert.Place.Slug]
+		if !ok {
+			i = len(groups)
+			index[concert.Place.Slug] = i
+			groups = append(groups, PlaceConcerts{Place: concert.Place})
+		}
+		groups[i].Dates = append(groups[i].Dates, concert.Date)
+	}
+	return groups
+}
+
+// Snapshot is the typed Groupie Trackers data, as of a cache refresh
+type Snapshot struct {
+	// Artists are ordered by ID
+	Artists []Artist
+	// FetchedAt is when the raw data was fetched from the API
+	FetchedAt time.Time
+	byID      map[int]int
+}
+
+// Artist returns the artist with the given ID. Returns false if there is no such artist.
+func (s *Snapshot) Artist(id int) (Artist, bool) {
+	if s == nil {
+		return Artist{}, false
+	}
+	i, ok := s.byID[id]
+	if !ok {
+		return Artist{}, false
+	}
+	return s.Artists[i], true
+}
+
+// newSnapshot returns a snapshot of the given artists, indexing them by ID
+func newSnapshot(artists []Artist, fetchedAt time.Time) *Snapshot {
+	sort.SliceStable(
+		artists, func(i, j int) bool {
+			return artists[i].ID < artists[j].ID
+		},
+	)
+
+	s := &Snapshot{Artists: artists, FetchedAt: fetchedAt, byID: make(map[int]int, len(artists))}
+	for i, artist := range artists {
+		s.byID[artist.ID] = i
+	}
+	return s
+}
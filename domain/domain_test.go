@@ -0,0 +1,539 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"groupie-tracker/api"
+	"groupie-tracker/location"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestNewSnapshot(t *testing.T) {
+	artists := []api.Artist{
+		{ID: 2, Name: "Queen", FirstAlbum: "14-12-1973"},
+		{ID: 1, Name: "Pink Floyd", FirstAlbum: "05-08-1967"},
+	}
+	locations := []api.Location{
+		{Locations: []string{"london-uk"}},
+		{Locations: []string{"los_angeles-usa", "london-uk"}},
+	}
+	relations := []api.Relations{
+		{DatesLocation: map[string][]string{"london-uk": {"01-01-1970"}}},
+		{
+			DatesLocation: map[string][]string{
+				"london-uk":       {"02-01-1980", "01-01-1980"},
+				"los_angeles-usa": {"01-01-1980"},
+			},
+		},
+	}
+
+	snapshot, err := NewSnapshot(artists, locations, relations, date(2024, time.January, 1))
+	if err != nil {
+		t.Fatalf("NewSnapshot() error = %v", err)
+	}
+
+	if len(snapshot.Artists) != 2 || snapshot.Artists[0].ID != 1 || snapshot.Artists[1].ID != 2 {
+		t.Fatalf("NewSnapshot() artists are not ordered by ID: %+v", snapshot.Artists)
+	}
+
+	queen, ok := snapshot.Artist(2)
+	if !ok {
+		t.Fatal("Artist(2) not found")
+	}
+	if !queen.FirstAlbum.Equal(date(1973, time.December, 14)) {
+		t.Errorf("FirstAlbum = %v, want 1973-12-14", queen.FirstAlbum)
+	}
+
+	var slugs []string
+	for _, place := range queen.Locations {
+		slugs = append(slugs, place.Slug)
+	}
+	if got := strings.Join(slugs, ","); got != "los_angeles-usa,london-uk" {
+		t.Errorf("Locations = %s, want los_angeles-usa,london-uk", got)
+	}
+
+	var concerts []string
+	for _, concert := range queen.Concerts {
+		concerts = append(concerts, concert.Date.Format(dateLayout)+" "+concert.Place.Slug)
+	}
+	want := "1980-01-01 london-uk,1980-01-01 los_angeles-usa,1980-01-02 london-uk"
+	if got := strings.Join(concerts, ","); got != want {
+		t.Errorf("Concerts = %s, want %s", got, want)
+	}
+
+	if _, ok := snapshot.Artist(3); ok {
+		t.Error("Artist(3) found, want not found")
+	}
+}
+
+func TestNewSnapshotInvalidDates(t *testing.T) {
+	artists := []api.Artist{{ID: 1, Name: "Queen", FirstAlbum: "someday"}}
+	relations := []api.Relations{
+		{Id: 1, DatesLocation: map[string][]string{"london-uk": {"01-01-1980", "31-02-1980"}}},
+	}
+
+	snapshot, err := NewSnapshot(artists, nil, relations, time.Time{})
+	if err == nil {
+		t.Fatal("NewSnapshot() error = nil, want an error")
+	}
+	for _, part := range []string{"first album", "concert at london-uk"} {
+		if !strings.Contains(err.Error(), part) {
+			t.Errorf("NewSnapshot() error = %v, want it to mention %q", err, part)
+		}
+	}
+
+	artist, ok := snapshot.Artist(1)
+	if !ok {
+		t.Fatal("Artist(1) not found")
+	}
+	if !artist.FirstAlbum.IsZero() {
+		t.Errorf("FirstAlbum = %v, want the zero time", artist.FirstAlbum)
+	}
+	if len(artist.Concerts) != 1 {
+		t.Errorf("Concerts = %v, want the single valid concert", artist.Concerts)
+	}
+}
+
+func TestArtistMarshalJSON(t *testing.T) {
+	snapshot, _ := NewSnapshot(
+		[]api.Artist{{ID: 1, Name: "Queen", Members: []string{"Freddie Mercury"}, CreationDate: 1970, FirstAlbum: "14-12-1973"}},
+		[]api.Location{{Id: 1, Locations: []string{"london-uk"}}},
+		[]api.Relations{{Id: 1, DatesLocation: map[string][]string{"london-uk": {"01-01-1980"}}}},
+		time.Time{},
+	)
+
+	got, err := json.Marshal(snapshot.Artists[0])
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(got, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if decoded["firstAlbum"] != "1973-12-14" || decoded["name"] != "Queen" || decoded["creationDate"] != 1970.0 ||
+		decoded["slug"] != "queen" {
+		t.Errorf("json.Marshal() = %s", got)
+	}
+	concerts, _ := decoded["concerts"].([]any)
+	if len(concerts) != 1 || concerts[0].(map[string]any)["date"] != "1980-01-01" {
+		t.Errorf("json.Marshal() concerts = %v", decoded["concerts"])
+	}
+}
+
+func TestArtistSlug(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Queen", want: "queen"},
+		{name: "Guns N' Roses", want: "guns-n-roses"},
+		{name: "  Thirty Seconds to Mars ", want: "thirty-seconds-to-mars"},
+		{name: "R3HAB", want: "r3hab"},
+		{name: "Mötley Crüe", want: "m-tley-cr-e"},
+		{name: "?!", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := (Artist{Name: tt.name}).Slug(); got != tt.want {
+			t.Errorf("Slug() of %q = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConcertsByPlace(t *testing.T) {
+	snapshot, _ := NewSnapshot(
+		[]api.Artist{{ID: 1, FirstAlbum: "14-12-1973"}},
+		[]api.Location{{Id: 1, Locations: []string{"paris-france", "london-uk"}}},
+		[]api.Relations{
+			{
+				Id: 1, DatesLocation: map[string][]string{
+					"london-uk":    {"02-01-1980", "01-01-1980"},
+					"paris-france": {"03-01-1980"},
+				},
+			},
+		},
+		time.Time{},
+	)
+
+	groups := snapshot.Artists[0].ConcertsByPlace()
+	if len(groups) != 2 {
+		t.Fatalf("ConcertsByPlace() = %v, want 2 groups", groups)
+	}
+	if groups[0].Place.Slug != "paris-france" || len(groups[0].Dates) != 1 {
+		t.Errorf("ConcertsByPlace()[0] = %v", groups[0])
+	}
+	if groups[1].Place.Slug != "london-uk" || len(groups[1].Dates) != 2 ||
+		!groups[1].Dates[0].Equal(date(1980, time.January, 1)) {
+		t.Errorf("ConcertsByPlace()[1] = %v", groups[1])
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	concert := func(slug string, year int, month time.Month, day int) Concert {
+		c := Concert{Date: date(year, month, day)}
+		c.Place.Slug = slug
+		return c
+	}
+	artist := Artist{
+		Concerts: []Concert{
+			concert("london-uk", 2019, time.March, 1),
+			concert("paris-france", 2019, time.June, 15),
+			concert("berlin-germany", 2020, time.January, 10),
+			concert("london-uk", 2020, time.June, 20),
+			concert("paris-france", 2021, time.May, 5),
+		},
+	}
+
+	tests := []struct {
+		name         string
+		now          time.Time
+		wantUpcoming []int
+		wantPast     []int
+	}{
+		{
+			name:         "All upcoming",
+			now:          date(2018, time.December, 31),
+			wantUpcoming: []int{2019, 2019, 2020, 2020, 2021},
+		},
+		{
+			name:     "All past",
+			now:      date(2022, time.January, 1),
+			wantPast: []int{2019, 2019, 2020, 2020, 2021},
+		},
+		{
+			name:         "Split within a year",
+			now:          date(2020, time.March, 1),
+			wantUpcoming: []int{2020, 2021},
+			wantPast:     []int{2019, 2019, 2020},
+		},
+		{
+			name:         "Concert today is upcoming",
+			now:          time.Date(2020, time.June, 20, 23, 30, 0, 0, time.UTC),
+			wantUpcoming: []int{2020, 2021},
+			wantPast:     []int{2019, 2019, 2020},
+		},
+	}
+
+	years := func(groups []YearConcerts) []int {
+		var result []int
+		for i, group := range groups {
+			if i > 0 && groups[i-1].Year >= group.Year {
+				t.Errorf("years are out of order: %d before %d", groups[i-1].Year, group.Year)
+			}
+			for _, c := range group.Concerts {
+				if c.Date.Year() != group.Year {
+					t.Errorf("concert on %v grouped in %d", c.Date, group.Year)
+				}
+				result = append(result, group.Year)
+			}
+		}
+		return result
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				timeline := artist.Timeline(tt.now)
+				if got := years(timeline.Upcoming); !slices.Equal(got, tt.wantUpcoming) {
+					t.Errorf("Timeline().Upcoming years = %v, want %v", got, tt.wantUpcoming)
+				}
+				if got := years(timeline.Past); !slices.Equal(got, tt.wantPast) {
+					t.Errorf("Timeline().Past years = %v, want %v", got, tt.wantPast)
+				}
+			},
+		)
+	}
+
+	if !(Artist{}).Timeline(date(2020, time.January, 1)).IsZero() {
+		t.Error("Timeline() of an artist without concerts is not zero")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	concert := func(slug, city, country string, year int, month time.Month, day int) Concert {
+		c := Concert{Date: date(year, month, day)}
+		c.Place.Slug, c.Place.City, c.Place.Country = slug, city, country
+		return c
+	}
+	london := func(year int, month time.Month, day int) Concert {
+		return concert("london-uk", "London", "United Kingdom", year, month, day)
+	}
+	paris := func(year int, month time.Month, day int) Concert {
+		return concert("paris-france", "Paris", "France", year, month, day)
+	}
+	berlin := func(year int, month time.Month, day int) Concert {
+		return concert("berlin-germany", "Berlin", "Germany", year, month, day)
+	}
+
+	queen := Artist{
+		ID:       1,
+		Members:  []string{"Freddie Mercury", "Brian May", "Roger Taylor", "John Deacon"},
+		Concerts: []Concert{london(2019, time.March, 1), paris(2019, time.June, 15), london(2020, time.June, 20)},
+	}
+	queen.Locations = []location.Place{queen.Concerts[0].Place, queen.Concerts[1].Place}
+	coldplay := Artist{
+		ID:       5,
+		Members:  []string{"Chris Martin"},
+		Concerts: []Concert{berlin(2019, time.January, 10), london(2019, time.March, 1), london(2019, time.March, 2)},
+	}
+	coldplay.Locations = []location.Place{coldplay.Concerts[0].Place, coldplay.Concerts[1].Place}
+	eagles := Artist{
+		ID:       12,
+		Concerts: []Concert{paris(2019, time.June, 16), berlin(2020, time.May, 5)},
+	}
+	eagles.Locations = []location.Place{eagles.Concerts[0].Place, eagles.Concerts[1].Place}
+
+	comparison := Compare([]Artist{queen, coldplay, eagles})
+
+	var summaries []string
+	for _, s := range comparison.Artists {
+		summaries = append(
+			summaries,
+			fmt.Sprintf("%d:%d:%d:%s", s.Artist.ID, s.MemberCount, s.ConcertCount, strings.Join(s.Countries, "+")),
+		)
+	}
+	wantSummaries := "1:4:3:France+United Kingdom,5:1:3:Germany+United Kingdom,12:0:2:France+Germany"
+	if got := strings.Join(summaries, ","); got != wantSummaries {
+		t.Errorf("Artists = %s, want %s", got, wantSummaries)
+	}
+
+	var shared []string
+	for _, p := range comparison.SharedPlaces {
+		var counts []string
+		for _, dates := range p.Dates {
+			counts = append(counts, fmt.Sprint(len(dates)))
+		}
+		shared = append(shared, fmt.Sprintf("%s:%s:%d", p.Place.Slug, strings.Join(counts, "+"), len(p.SameDates)))
+	}
+	wantShared := "berlin-germany:0+1+1:0,london-uk:2+2+0:1,paris-france:1+0+1:0"
+	if got := strings.Join(shared, ","); got != wantShared {
+		t.Errorf("SharedPlaces = %s, want %s", got, wantShared)
+	}
+
+	londonShared := comparison.SharedPlaces[1]
+	if !londonShared.SameDate(date(2019, time.March, 1)) || londonShared.SameDate(date(2019, time.March, 2)) {
+		t.Errorf("London SameDates = %v, want [2019-03-01]", londonShared.SameDates)
+	}
+
+	encoded, err := json.Marshal(comparison)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	for _, want := range []string{
+		`"memberCount":4`,
+		`"countries":["France","United Kingdom"]`,
+		`"dates":{"1":["2019-03-01","2020-06-20"],"5":["2019-03-01","2019-03-02"]}`,
+		`"sameDates":["2019-03-01"]`,
+	} {
+		if !strings.Contains(string(encoded), want) {
+			t.Errorf("json.Marshal() = %s, want it to contain %s", encoded, want)
+		}
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	concert := func(slug string, year int, month time.Month, day int) Concert {
+		c := Concert{Date: date(year, month, day)}
+		c.Place.Slug = slug
+		return c
+	}
+	snapshot := newSnapshot(
+		[]Artist{
+			{
+				ID:   1,
+				Name: "Queen",
+				Concerts: []Concert{
+					concert("london-uk", 2019, time.March, 1),
+					concert("paris-france", 2019, time.June, 15),
+				},
+			},
+			{
+				ID:   2,
+				Name: "Coldplay",
+				Concerts: []Concert{
+					concert("london-uk", 2019, time.March, 1),
+					concert("paris-france", 2019, time.June, 17),
+				},
+			},
+			{
+				ID:       3,
+				Name:     "Eagles",
+				Concerts: []Concert{concert("london-uk", 2019, time.February, 27)},
+			},
+			{
+				ID:       4,
+				Name:     "ACDC",
+				Concerts: []Concert{concert("berlin-germany", 2019, time.March, 1)},
+			},
+		}, date(2024, time.January, 1),
+	)
+
+	tests := []struct {
+		name   string
+		id     int
+		window int
+		want   string
+		wantOk bool
+	}{
+		{
+			name:   "Same date",
+			id:     1,
+			window: 0,
+			want:   "Coldplay:london-uk+0",
+			wantOk: true,
+		},
+		{
+			name:   "Festival window",
+			id:     1,
+			window: 2,
+			want:   "Coldplay:london-uk+0,paris-france+2;Eagles:london-uk+2",
+			wantOk: true,
+		},
+		{
+			name:   "No overlaps",
+			id:     4,
+			window: 31,
+			want:   "",
+			wantOk: true,
+		},
+		{
+			name:   "Unknown artist",
+			id:     5,
+			window: 3,
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, ok := snapshot.Overlaps(tt.id, tt.window)
+				if ok != tt.wantOk {
+					t.Fatalf("Overlaps() ok = %v, want %v", ok, tt.wantOk)
+				}
+
+				var artists []string
+				for _, a := range got {
+					var overlaps []string
+					for _, o := range a.Overlaps {
+						overlaps = append(overlaps, fmt.Sprintf("%s+%d", o.Place.Slug, o.DaysApart()))
+					}
+					artists = append(artists, a.Artist.Name+":"+strings.Join(overlaps, ","))
+				}
+				if result := strings.Join(artists, ";"); result != tt.want {
+					t.Errorf("Overlaps() = %s, want %s", result, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestTour(t *testing.T) {
+	london, _ := location.Normalize("london-uk")
+	paris, _ := location.Normalize("paris-france")
+	texas, _ := location.Normalize("texas-usa")
+	unknown := location.Place{Slug: "atlantis-sea", City: "Atlantis", Country: "Sea"}
+	concertIn := func(place location.Place, year int, month time.Month, day int) Concert {
+		return Concert{Place: place, Date: date(year, month, day)}
+	}
+
+	tests := []struct {
+		name          string
+		concerts      []Concert
+		wantCities    int
+		wantCountries int
+		wantGapDays   int
+		wantKm        float64
+		wantUnlocated int
+	}{
+		{
+			name:     "No concerts",
+			concerts: nil,
+		},
+		{
+			name:          "Single concert",
+			concerts:      []Concert{concertIn(london, 2019, time.March, 1)},
+			wantCities:    1,
+			wantCountries: 1,
+		},
+		{
+			name: "Round trip",
+			concerts: []Concert{
+				concertIn(london, 2019, time.March, 1),
+				concertIn(paris, 2019, time.March, 3),
+				concertIn(london, 2019, time.March, 13),
+			},
+			wantCities:    2,
+			wantCountries: 2,
+			wantGapDays:   10,
+			wantKm:        2 * london.DistanceTo(paris),
+		},
+		{
+			name: "Unlocated legs are left out",
+			concerts: []Concert{
+				concertIn(london, 2019, time.March, 1),
+				concertIn(unknown, 2019, time.April, 1),
+				concertIn(paris, 2019, time.April, 2),
+			},
+			wantCities:    3,
+			wantCountries: 3,
+			wantGapDays:   31,
+			wantUnlocated: 1,
+		},
+		{
+			name: "Regions are not cities",
+			concerts: []Concert{
+				concertIn(london, 2019, time.March, 1),
+				concertIn(texas, 2019, time.March, 11),
+			},
+			wantCities:    1,
+			wantCountries: 2,
+			wantGapDays:   10,
+			wantKm:        london.DistanceTo(texas),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tour := Artist{Concerts: tt.concerts}.Tour()
+
+				if tour.Concerts != len(tt.concerts) || tour.Cities != tt.wantCities || tour.Countries != tt.wantCountries {
+					t.Errorf(
+						"Tour() concerts, cities, countries = %d, %d, %d, want %d, %d, %d",
+						tour.Concerts, tour.Cities, tour.Countries, len(tt.concerts), tt.wantCities, tt.wantCountries,
+					)
+				}
+				if tour.LongestGap.Days() != tt.wantGapDays {
+					t.Errorf("Tour() longest gap = %d days, want %d", tour.LongestGap.Days(), tt.wantGapDays)
+				}
+				if math.Abs(tour.DistanceKm-tt.wantKm) > 1e-6 || tour.Unlocated != tt.wantUnlocated {
+					t.Errorf(
+						"Tour() distance, unlocated = %v, %d, want %v, %d",
+						tour.DistanceKm, tour.Unlocated, tt.wantKm, tt.wantUnlocated,
+					)
+				}
+				if len(tt.concerts) > 0 && (tour.First != tt.concerts[0] || tour.Last != tt.concerts[len(tt.concerts)-1]) {
+					t.Errorf("Tour() first, last = %v, %v", tour.First, tour.Last)
+				}
+
+				encoded, err := json.Marshal(tour)
+				if err != nil {
+					t.Fatalf("json.Marshal() error = %v", err)
+				}
+				if wantNull := len(tt.concerts) < 2; strings.Contains(string(encoded), `"longestGap":null`) != wantNull {
+					t.Errorf("json.Marshal() = %s, want longestGap null: %v", encoded, wantNull)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"encoding/json"
+	"groupie-tracker/location"
+	"sort"
+	"time"
+)
+
+// Overlap is a pair of concerts by two artists, held in the same city within a window of days
+type Overlap struct {
+	Place location.Place
+	// Date is the date of the artist's concert
+	Date time.Time
+	// OtherDate is the date of the other artist's concert
+	OtherDate time.Time
+}
+
+// DaysApart returns the number of days between the two concerts, 0 if they were held on the same date
+func (o Overlap) DaysApart() int {
+	days := int(o.OtherDate.Sub(o.Date).Hours() / 24)
+	if days < 0 {
+		return -days
+	}
+	return days
+}
+
+// MarshalJSON encodes the overlap, with the dates in the YYYY-MM-DD format
+func (o Overlap) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			Place     location.Place `json:"place"`
+			Date      string         `json:"date"`
+			OtherDate string         `json:"otherDate"`
+			DaysApart int            `json:"daysApart"`
+		}{
+			Place:     o.Place,
+			Date:      o.Date.Format(dateLayout),
+			OtherDate: o.OtherDate.Format(dateLayout),
+			DaysApart: o.DaysApart(),
+		},
+	)
+}
+
+// ArtistOverlaps holds the concerts an artist played alongside another artist
+type ArtistOverlaps struct {
+	// Artist is the other artist
+	Artist Artist
+	// Overlaps are in chronological order of the artist's concerts
+	Overlaps []Overlap
+}
+
+// MarshalJSON encodes the other artist's ID, name and image, and the overlaps
+func (a ArtistOverlaps) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			ID       int       `json:"id"`
+			Name     string    `json:"name"`
+			Image    string    `json:"image"`
+			Overlaps []Overlap `json:"overlaps"`
+		}{ID: a.Artist.ID, Name: a.Artist.Name, Image: a.Artist.Image, Overlaps: a.Overlaps},
+	)
+}
+
+// Overlaps returns the artists who played in the same city as the given artist, on the same date or within
+// window days of one of its concerts, e.g. at the same festival. The artists are ordered by the number of
+// overlaps, most first, then by name. Returns false if there is no artist with the given ID.
+func (s *Snapshot) Overlaps(id int, window int) ([]ArtistOverlaps, bool) {
+	artist, ok := s.Artist(id)
+	if !ok {
+		return nil, false
+	}
+
+	bySlug := make(map[string][]time.Time)
+	for _, concert := range artist.Concerts {
+		bySlug[concert.Place.Slug] = append(bySlug[concert.Place.Slug], concert.Date)
+	}
+
+	maxApart := time.Duration(window) * 24 * time.Hour
+	var result []ArtistOverlaps
+	for _, other := range s.Artists {
+		if other.ID == artist.ID {
+			continue
+		}
+
+		var overlaps []Overlap
+		for _, concert := range other.Concerts {
+			for _, date := range bySlug[concert.Place.Slug] {
+				apart := concert.Date.Sub(date)
+				if apart < 0 {
+					apart = -apart
+				}
+				if apart <= maxApart {
+					overlaps = append(overlaps, Overlap{Place: concert.Place, Date: date, OtherDate: concert.Date})
+				}
+			}
+		}
+		if len(overlaps) == 0 {
+			continue
+		}
+
+		sort.SliceStable(
+			overlaps, func(i, j int) bool {
+				if !overlaps[i].Date.Equal(overlaps[j].Date) {
+					return overlaps[i].Date.Before(overlaps[j].Date)
+				}
+				return overlaps[i].OtherDate.Before(overlaps[j].OtherDate)
+			},
+		)
+		result = append(result, ArtistOverlaps{Artist: other, Overlaps: overlaps})
+	}
+
+	sort.SliceStable(
+		result, func(i, j int) bool {
+			if len(result[i].Overlaps) != len(result[j].Overlaps) {
+				return len(result[i].Overlaps) > len(result[j].Overlaps)
+			}
+			return result[i].Artist.Name < result[j].Artist.Name
+		},
+	)
+	return result, true
+}
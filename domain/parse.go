@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"groupie-tracker/api"
+	"groupie-tracker/location"
+	"groupie-tracker/xtime"
+	"sort"
+	"time"
+)
+
+// NewSnapshot converts the raw Groupie Trackers API payloads into a typed snapshot, fetched at the given time.
+// The locations and relations are matched to the artists by ID, or by position in the index if they carry no ID.
+//
+// Dates that can't be parsed are left out of the snapshot, or, for first albums, left as the zero time.
+// The returned error, if not nil, describes every such date, but the snapshot is still usable.
+func NewSnapshot(
+	artists []api.Artist, locations []api.Location, relations []api.Relations, fetchedAt time.Time,
+) (*Snapshot, error) {
+	locationsByID := make(map[int][]string, len(locations))
+	for i, loc := range locations {
+		locationsByID[indexID(loc.Id, i)] = loc.Locations
+	}
+
+	relationsByID := make(map[int]map[string][]string, len(relations))
+	for i, relation := range relations {
+		relationsByID[indexID(relation.Id, i)] = relation.DatesLocation
+	}
+
+	var errs []error
+	result := make([]Artist, 0, len(artists))
+	for _, raw := range artists {
+		artist, err := newArtist(raw, locationsByID[raw.ID], relationsByID[raw.ID])
+		if err != nil {
+			errs = append(errs, err)
+		}
+		result = append(result, artist)
+	}
+
+	return newSnapshot(result, fetchedAt), errors.Join(errs...)
+}
+
+// indexID returns the artist ID of the i-th entry of an API index, whose own ID is id.
+// The IDs of the API indexes start at 1.
+func indexID(id, i int) int {
+	if id == 0 {
+		return i + 1
+	}
+	return id
+}
+
+// newArtist converts the raw artist, with its hyphenated concert locations and the dates of the concerts
+// held at each location, into a typed artist
+func newArtist(raw api.Artist, slugs []string, datesLocations map[string][]string) (Artist, error) {
+	var errs []error
+	artist := Artist{
+		ID:           raw.ID,
+		Image:        raw.Image,
+		Name:         raw.Name,
+		Members:      raw.Members,
+		CreationDate: raw.CreationDate,
+		Raw:          raw,
+	}
+
+	if firstAlbum, err := xtime.Parse(raw.FirstAlbum); err == nil {
+		artist.FirstAlbum = firstAlbum
+	} else {
+		errs = append(errs, fmt.Errorf("first album: %w", err))
+	}
+
+	// concert locations listed in the relations, but not the locations index, are added in alphabetical order
+	var extraSlugs []string
+	for slug := range datesLocations {
+		extraSlugs = append(extraSlugs, slug)
+	}
+	sort.Strings(extraSlugs)
+
+	places := make(map[string]location.Place)
+	for _, slug := range append(append([]string(nil), slugs...), extraSlugs...) {
+		if _, ok := places[slug]; ok {
+			continue
+		}
+		places[slug] = location.PlaceOf(slug)
+		artist.Locations = append(artist.Locations, places[slug])
+	}
+
+	for slug, dates := range datesLocations {
+		for _, date := range dates {
+			t, err := xtime.Parse(date)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("concert at %s: %w", slug, err))
+				continue
+			}
+			artist.Concerts = append(artist.Concerts, Concert{Place: places[slug], Date: t})
+		}
+	}
+	SortConcerts(artist.Concerts)
+
+	if len(errs) > 0 {
+		return artist, fmt.Errorf("artist %d (%s): %w", raw.ID, raw.Name, errors.Join(errs...))
+	}
+	return artist, nil
+}
+
+// SortConcerts sorts the concerts in chronological order, breaking ties by location slug
+func SortConcerts(concerts []Concert) {
+	sort.SliceStable(
+		concerts, func(i, j int) bool {
+			if !concerts[i].Date.Equal(concerts[j].Date) {
+				return concerts[i].Date.Before(concerts[j].Date)
+			}
+			return concerts[i].Place.Slug < concerts[j].Place.Slug
+		},
+	)
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// YearConcerts holds the concerts held in a year
+type YearConcerts struct {
+	Year int
+	// Concerts are in chronological order
+	Concerts []Concert
+}
+
+// Timeline holds an artist's concerts, split into upcoming and past relative to a point in time, and grouped by year
+type Timeline struct {
+	// Upcoming are the concerts held on or after the day of the reference time, soonest first
+	Upcoming []YearConcerts
+	// Past are the concerts held before the day of the reference time, oldest first
+	Past []YearConcerts
+}
+
+// IsZero reports whether the timeline holds no concerts
+func (t Timeline) IsZero() bool {
+	return len(t.Upcoming) == 0 && len(t.Past) == 0
+}
+
+// Timeline returns the artist's concerts, split into upcoming and past relative to now. A concert held on the day
+// of now is upcoming.
+func (a Artist) Timeline(now time.Time) Timeline {
+	// concert dates have no time of day, compare them to the start of the current day
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	var timeline Timeline
+	for _, concert := range a.Concerts {
+		if concert.Date.Before(today) {
+			timeline.Past = appendByYear(timeline.Past, concert)
+		} else {
+			timeline.Upcoming = appendByYear(timeline.Upcoming, concert)
+		}
+	}
+	return timeline
+}
+
+// appendByYear appends the concert to the last group if it is of the same year, or to a new group otherwise.
+// The concerts must be appended in chronological order.
+func appendByYear(groups []YearConcerts, concert Concert) []YearConcerts {
+	year := concert.Date.Year()
+	if n := len(groups); n > 0 && groups[n-1].Year == year {
+		groups[n-1].Concerts = append(groups[n-1].Concerts, concert)
+		return groups
+	}
+	return append(groups, YearConcerts{Year: year, Concerts: []Concert{concert}})
+}
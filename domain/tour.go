@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// Gap is the time between two consecutive concerts
+type Gap struct {
+	From Concert
+	To   Concert
+}
+
+// Days returns the number of days between the two concerts
+func (g Gap) Days() int {
+	return int(g.To.Date.Sub(g.From.Date).Hours() / 24)
+}
+
+// Tour holds the metrics of an artist's concerts, along their chronological route
+type Tour struct {
+	Concerts int
+	// Cities and Countries are the numbers of distinct cities and countries. The places of the slugs that name
+	// a region or a country, e.g. `texas-usa`, have no city, and are left out of Cities.
+	Cities    int
+	Countries int
+	// First and Last are the earliest and latest concerts, zero if there are none
+	First Concert
+	Last  Concert
+	// LongestGap is the longest time between two consecutive concerts, zero if there are fewer than two
+	LongestGap Gap
+	// DistanceKm is the great-circle distance travelled from concert to concert, in chronological order,
+	// along the legs between located places
+	DistanceKm float64
+	// Unlocated is the number of concerts held at places without coordinates, whose legs are left out of DistanceKm
+	Unlocated int
+}
+
+// MarshalJSON encodes the tour, with the dates in the YYYY-MM-DD format and the distance rounded to the kilometre
+func (t Tour) MarshalJSON() ([]byte, error) {
+	type gap struct {
+		From Concert `json:"from"`
+		To   Concert `json:"to"`
+		Days int     `json:"days"`
+	}
+
+	payload := struct {
+		Concerts   int      `json:"concerts"`
+		Cities     int      `json:"cities"`
+		Countries  int      `json:"countries"`
+		First      *Concert `json:"first"`
+		Last       *Concert `json:"last"`
+		LongestGap *gap     `json:"longestGap"`
+		DistanceKm float64  `json:"distanceKm"`
+		Unlocated  int      `json:"unlocated"`
+	}{
+		Concerts:   t.Concerts,
+		Cities:     t.Cities,
+		Countries:  t.Countries,
+		DistanceKm: math.Round(t.DistanceKm),
+		Unlocated:  t.Unlocated,
+	}
+	if t.Concerts > 0 {
+		payload.First, payload.Last = &t.First, &t.Last
+	}
+	if t.Concerts > 1 {
+		payload.LongestGap = &gap{From: t.LongestGap.From, To: t.LongestGap.To, Days: t.LongestGap.Days()}
+	}
+	return json.Marshal(payload)
+}
+
+// Tour returns the metrics of the artist's concerts
+func (a Artist) Tour() Tour {
+	tour := Tour{Concerts: len(a.Concerts)}
+	if tour.Concerts == 0 {
+		return tour
+	}
+	tour.First, tour.Last = a.Concerts[0], a.Concerts[len(a.Concerts)-1]
+
+	cities := make(map[string]bool)
+	countries := make(map[string]bool)
+	for i, concert := range a.Concerts {
+		if concert.Place.City != "" {
+			cities[concert.Place.Slug] = true
+		}
+		if concert.Place.Country != "" {
+			countries[concert.Place.Country] = true
+		}
+		if !concert.Place.Located() {
+			tour.Unlocated++
+		}
+		if i == 0 {
+			continue
+		}
+
+		previous := a.Concerts[i-1]
+		gap := Gap{From: previous, To: concert}
+		if i == 1 || gap.Days() > tour.LongestGap.Days() {
+			tour.LongestGap = gap
+		}
+		if previous.Place.Located() && concert.Place.Located() {
+			tour.DistanceKm += previous.Place.DistanceTo(concert.Place)
+		}
+	}
+	tour.Cities, tour.Countries = len(cities), len(countries)
+	return tour
+}
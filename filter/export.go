@@ -0,0 +1,190 @@
+package filter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"groupie-tracker/domain"
+	"groupie-tracker/httperr"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ExportFormat is the format the filter results are exported in
+type ExportFormat string
+
+const (
+	// ExportCSV writes one CSV row per artist
+	ExportCSV ExportFormat = "csv"
+	// ExportJSONLines writes one JSON artist object per line
+	ExportJSONLines ExportFormat = "jsonl"
+	// ExportConcerts writes one CSV row per concert, with the artist, member count, location and date
+	ExportConcerts ExportFormat = "concerts"
+)
+
+// exportFiles describe the downloads of the export formats
+var exportFiles = map[ExportFormat]struct {
+	contentType string
+	fileName    string
+	write       func(w io.Writer, artists []domain.Artist) error
+}{
+	ExportCSV:       {contentType: "text/csv; charset=utf-8", fileName: "artists.csv", write: writeArtistsCSV},
+	ExportJSONLines: {contentType: "application/x-ndjson", fileName: "artists.jsonl", write: writeArtistsJSONLines},
+	ExportConcerts:  {contentType: "text/csv; charset=utf-8", fileName: "concerts.csv", write: writeConcertsCSV},
+}
+
+// listSeparator separates the values of list fields, such as the members, in CSV cells
+const listSeparator = "; "
+
+// csvText returns the text cell of a CSV file, prefixed with a quote if it starts like a formula, e.g. `=1+1`,
+// so that spreadsheets opening the download show it as text rather than run it
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// Export returns the handler of POST requests to /api/filter/export. It accepts the same APIRequestData as API,
+// and streams the matching artists of the store as a download, in the format given by the `format` query
+// parameter: `csv` (the default), `jsonl` or `concerts`. The errors are written with writer.
+func Export(store Store, writer *httperr.Writer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format := ExportFormat(strings.ToLower(r.URL.Query().Get("format")))
+		if format == "" {
+			format = ExportCSV
+		}
+		file, ok := exportFiles[format]
+		if !ok {
+			makeRequestErrorResponse(
+				w, r, writer, ValidationErrors{
+					{Field: "format", Code: CodeInvalidValue, Message: fmt.Sprintf("unknown export format %q", format)},
+				},
+			)
+			return
+		}
+
+		// Read and validate JSON from the request body
+		requestData, err := DecodeRequest(limitBody(w, r))
+		if err != nil {
+			makeRequestErrorResponse(w, r, writer, err)
+			return
+		}
+
+		snapshot, err := store.Snapshot()
+		if err != nil {
+			makeRequestErrorResponse(w, r, writer, err)
+			return
+		}
+
+		filteredArtists, err := Apply(snapshot.Artists, requestData)
+		if err != nil {
+			makeRequestErrorResponse(w, r, writer, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", file.contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.fileName))
+		// the download is streamed, so errors can only be reported by cutting it short
+		if err := file.write(w, filteredArtists); err != nil {
+			log.Printf("Error exporting the filtered artists: %v\n", err)
+		}
+	}
+}
+
+// writeArtistsCSV writes the artists as CSV, one row per artist
+func writeArtistsCSV(w io.Writer, artists []domain.Artist) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write(
+		[]string{"id", "name", "members", "member_count", "creation_date", "first_album", "locations", "concert_count"},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, artist := range artists {
+		locations := make([]string, len(artist.Locations))
+		for i, place := range artist.Locations {
+			locations[i] = place.String()
+		}
+
+		err := cw.Write(
+			[]string{
+				strconv.Itoa(artist.ID),
+				csvText(artist.Name),
+				csvText(strings.Join(artist.Members, listSeparator)),
+				strconv.Itoa(len(artist.Members)),
+				strconv.Itoa(artist.CreationDate),
+				formatFirstAlbum(artist),
+				csvText(strings.Join(locations, listSeparator)),
+				strconv.Itoa(len(artist.Concerts)),
+			},
+		)
+		if err != nil {
+			return err
+		}
+		// flush each row, so that the download is streamed, and stop at the first error
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeArtistsJSONLines writes the artists as JSON Lines, one artist object per line, as in the API responses
+func writeArtistsJSONLines(w io.Writer, artists []domain.Artist) error {
+	encoder := json.NewEncoder(w)
+	for _, artist := range newAPIArtists(artists) {
+		if err := encoder.Encode(artist); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeConcertsCSV writes the artists' concerts as CSV, one row per concert
+func writeConcertsCSV(w io.Writer, artists []domain.Artist) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"artist_id", "artist", "member_count", "location", "country_code", "date"}); err != nil {
+		return err
+	}
+
+	for _, artist := range artists {
+		for _, concert := range artist.Concerts {
+			err := cw.Write(
+				[]string{
+					strconv.Itoa(artist.ID),
+					csvText(artist.Name),
+					strconv.Itoa(len(artist.Members)),
+					csvText(concert.Place.String()),
+					concert.Place.CountryCode,
+					concert.Date.Format("2006-01-02"),
+				},
+			)
+			if err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatFirstAlbum returns the first album date of the artist in the YYYY-MM-DD format, or blank if unknown
+func formatFirstAlbum(artist domain.Artist) string {
+	if artist.FirstAlbum.IsZero() {
+		return ""
+	}
+	return artist.FirstAlbum.Format("2006-01-02")
+}
@@ -0,0 +1,163 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"groupie-tracker/api"
+	"groupie-tracker/domain"
+	"groupie-tracker/location"
+	"strings"
+	"testing"
+	"time"
+)
+
+func exportArtists() []domain.Artist {
+	london, _ := location.Normalize("london-uk")
+	dallas, _ := location.Normalize("dallas-usa")
+	return []domain.Artist{
+		{
+			ID:           1,
+			Name:         "Queen",
+			Members:      []string{"Freddie Mercury", "Brian May"},
+			CreationDate: 1970,
+			FirstAlbum:   time.Date(1973, time.December, 14, 0, 0, 0, 0, time.UTC),
+			Locations:    []location.Place{london, dallas},
+			Concerts: []domain.Concert{
+				{Place: london, Date: time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)},
+				{Place: dallas, Date: time.Date(1980, time.February, 2, 0, 0, 0, 0, time.UTC)},
+			},
+			Raw: api.Artist{
+				ID: 1, Name: "Queen", Members: []string{"Freddie Mercury", "Brian May"}, CreationDate: 1970,
+				FirstAlbum: "14-12-1973", Locations: "https://groupietrackers.herokuapp.com/api/locations/1",
+			},
+		},
+		{
+			ID: 2, Name: "Eminem, \"Slim\"", Members: []string{"Marshall Mathers"}, CreationDate: 1996,
+			Raw: api.Artist{ID: 2, Name: "Eminem, \"Slim\"", Members: []string{"Marshall Mathers"}, CreationDate: 1996},
+		},
+	}
+}
+
+func TestExportWriters(t *testing.T) {
+	tests := []struct {
+		name   string
+		format ExportFormat
+		want   string
+	}{
+		{
+			name:   "Artists CSV",
+			format: ExportCSV,
+			want: "id,name,members,member_count,creation_date,first_album,locations,concert_count\n" +
+				"1,Queen,Freddie Mercury; Brian May,2,1970,1973-12-14,\"London, England, United Kingdom; Dallas, Texas, United States\",2\n" +
+				"2,\"Eminem, \"\"Slim\"\"\",Marshall Mathers,1,1996,,,0\n",
+		},
+		{
+			name:   "Concerts CSV",
+			format: ExportConcerts,
+			want: "artist_id,artist,member_count,location,country_code,date\n" +
+				"1,Queen,2,\"London, England, United Kingdom\",GB,1980-01-01\n" +
+				"1,Queen,2,\"Dallas, Texas, United States\",US,1980-02-02\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var buf bytes.Buffer
+				if err := exportFiles[tt.format].write(&buf, exportArtists()); err != nil {
+					t.Fatalf("write() error = %v", err)
+				}
+				if got := buf.String(); got != tt.want {
+					t.Errorf("write() =\n%s\nwant\n%s", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestExportCSVFormulas(t *testing.T) {
+	artists := []domain.Artist{
+		{ID: 1, Name: "=HYPERLINK(\"https://example.com\")", Members: []string{"@admin", "Brian May"}},
+		{ID: 2, Name: "-M-", Members: []string{"+1"}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeArtistsCSV(&buf, artists); err != nil {
+		t.Fatalf("writeArtistsCSV() error = %v", err)
+	}
+	want := "id,name,members,member_count,creation_date,first_album,locations,concert_count\n" +
+		"1,\"'=HYPERLINK(\"\"https://example.com\"\")\",'@admin; Brian May,2,0,,,0\n" +
+		"2,'-M-,'+1,1,0,,,0\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writeArtistsCSV() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestExportWriteError(t *testing.T) {
+	for format, file := range exportFiles {
+		if err := file.write(failingWriter{}, exportArtists()); err == nil {
+			t.Errorf("%s write() error = nil, want the error of the writer", format)
+		}
+	}
+}
+
+func TestExportJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeArtistsJSONLines(&buf, exportArtists()); err != nil {
+		t.Fatalf("writeArtistsJSONLines() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("writeArtistsJSONLines() wrote %d lines, want 2", len(lines))
+	}
+	for i, line := range lines {
+		var artist struct {
+			ID         int    `json:"id"`
+			Slug       string `json:"slug"`
+			FirstAlbum string `json:"firstAlbum"`
+			Locations  string `json:"locations"`
+		}
+		if err := json.Unmarshal([]byte(line), &artist); err != nil {
+			t.Fatalf("line %d is not an artist object of the API: %v", i, err)
+		}
+		if artist.ID != i+1 {
+			t.Errorf("line %d has artist ID %d, want %d", i, artist.ID, i+1)
+		}
+	}
+
+	// the artists are encoded as sent by the Groupie Trackers API, along with their slug
+	want := `{"id":1,"image":"","name":"Queen","members":["Freddie Mercury","Brian May"],"creationDate":1970,` +
+		`"firstAlbum":"14-12-1973","locations":"https://groupietrackers.herokuapp.com/api/locations/1",` +
+		`"concertDates":"","relations":"","slug":"queen"}`
+	if lines[0] != want {
+		t.Errorf("writeArtistsJSONLines() first line =\n%s\nwant\n%s", lines[0], want)
+	}
+}
+
+func TestApply(t *testing.T) {
+	artists := exportArtists()
+
+	result, err := Apply(
+		artists, APIRequestData{
+			NumberOfMembersFilterQuery: NumberOfMembersFilterQuery{In: []int{2}, Type: "in"},
+			Combinator:                 "and",
+		},
+	)
+	if err != nil || len(result) != 1 || result[0].ID != 1 {
+		t.Errorf("Apply() = %v, %v, want Queen", result, err)
+	}
+
+	_, err = Apply(artists, APIRequestData{CreationDateFilterQuery: CreationDateFilterQuery{Type: "between"}})
+	if err == nil {
+		t.Error("Apply() error = nil, want an error for the invalid creation_date query type")
+	}
+}
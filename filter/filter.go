@@ -5,7 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"groupie-tracker/api"
-	"groupie-tracker/cache"
+	"groupie-tracker/domain"
+	"groupie-tracker/httperr"
 	"groupie-tracker/location"
 	"groupie-tracker/xtime"
 	"log"
@@ -50,67 +51,144 @@ type LocationsOfConcertsFilterQuery struct {
 	In []string `json:"in"`
 }
 
+// NearFilterQuery matches concert locations within RadiusKm kilometres of a center point.
+// The center is either the place named by Location, a location slug (`berlin-germany`) or city name (`Berlin`),
+// or, if Location is blank, the coordinates Lat and Lon.
+type NearFilterQuery struct {
+	Location string  `json:"location"`
+	Lat      float64 `json:"lat"`
+	Lon      float64 `json:"lon"`
+	RadiusKm float64 `json:"radius_km"`
+}
+
+// GeographyFilterQuery matches artists with at least one concert location that satisfies every given criteria:
+// it is in one of the Continents (names or codes, e.g. `Europe` or `EU`), in one of the countries with the
+// ISO 3166 CountryCodes (e.g. `DE`), and within the Near radius.
+type GeographyFilterQuery struct {
+	Continents   []string         `json:"continents"`
+	CountryCodes []string         `json:"country_codes"`
+	Near         *NearFilterQuery `json:"near"`
+}
+
+// IsZero reports whether the query has no criteria
+func (q GeographyFilterQuery) IsZero() bool {
+	return len(q.Continents) == 0 && len(q.CountryCodes) == 0 && q.Near == nil
+}
+
 type APIRequestData struct {
 	CreationDateFilterQuery        `json:"creation_date"`
 	FirstAlbumDateFilterQuery      `json:"first_album_date"`
 	LocationsOfConcertsFilterQuery `json:"locations_of_concerts"`
 	NumberOfMembersFilterQuery     `json:"number_of_members"`
+	GeographyFilterQuery           `json:"geography"`
 	Combinator                     string `json:"combinator"`
 	Query                          string `json:"query"`
 }
 
 type APIResponseData struct {
-	Status  int          `json:"status"`
-	Artists []api.Artist `json:"artists"`
+	Status  int         `json:"status"`
+	Artists []APIArtist `json:"artists"`
 }
 
-type APIErrorResponse struct {
-	Status  int    `json:"status"`
-	Message string `json:"message"`
+// APIArtist is an artist of the API responses: the artist as sent by the Groupie Trackers API, along with the
+// slug of its page URL, see domain.Artist.Slug
+type APIArtist struct {
+	api.Artist
+	Slug string `json:"slug"`
 }
 
-func makeAPIErrorResponse(w http.ResponseWriter, statusCode int, message string) {
-	w.WriteHeader(statusCode)
-	w.Header().Set("Content-Type", "application/json")
-	response := APIErrorResponse{
-		Status:  statusCode,
-		Message: message,
+// newAPIArtists returns the API response objects of the artists
+func newAPIArtists(artists []domain.Artist) []APIArtist {
+	result := make([]APIArtist, 0, len(artists))
+	for _, artist := range artists {
+		result = append(result, APIArtist{Artist: artist.Raw, Slug: artist.Slug()})
 	}
-	_ = json.NewEncoder(w).Encode(response)
+	return result
 }
 
-func API(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		makeAPIErrorResponse(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+// makeRequestErrorResponse responds with the error of a filter request, written with writer, as an RFC 9457
+// problem details object by default: a 400 Bad Request listing the invalid fields for ValidationErrors, and
+// otherwise, the status code mapped from the xerrors sentinel the error wraps, e.g. 503 Service Unavailable if the
+// artists can't be fetched
+func makeRequestErrorResponse(w http.ResponseWriter, r *http.Request, writer *httperr.Writer, err error) {
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		writer.Error(w, r, httperr.JSON, err)
 		return
 	}
 
-	// Set content-type to application/json
-	w.Header().Set("Content-Type", "application/json")
+	problem := httperr.New(http.StatusBadRequest, "Invalid filter request")
+	problem.Errors = errs
+	writer.Write(w, r, httperr.JSON, problem)
+}
 
-	// Read JSON from the request body
-	var requestData APIRequestData
-	err := json.NewDecoder(r.Body).Decode(&requestData)
-	if err != nil {
-		makeAPIErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
-		return
+// Store is the source of the artists to filter, e.g. a cache.Cache
+type Store interface {
+	Snapshot() (*domain.Snapshot, error)
+}
+
+// API returns the handler of POST requests to /api/filter, which responds with the artists of the store matching
+// the APIRequestData of the request body. The errors are written with writer, see makeRequestErrorResponse.
+func API(store Store, writer *httperr.Writer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Read and validate JSON from the request body
+		requestData, err := DecodeRequest(limitBody(w, r))
+		if err != nil {
+			makeRequestErrorResponse(w, r, writer, err)
+			return
+		}
+
+		snapshot, err := store.Snapshot()
+		if err != nil {
+			makeRequestErrorResponse(w, r, writer, err)
+			return
+		}
+
+		filteredArtists, err := Apply(snapshot.Artists, requestData)
+		if err != nil {
+			makeRequestErrorResponse(w, r, writer, err)
+			return
+		}
+
+		// Set content-type to application/json
+		w.Header().Set("Content-Type", "application/json")
+
+		// Create a response
+		responseData := APIResponseData{
+			Status:  200,
+			Artists: newAPIArtists(filteredArtists),
+		}
+
+		// Encode the response data as JSON and send it
+		err = json.NewEncoder(w).Encode(responseData)
+		if err != nil {
+			log.Printf("Error encoding response: %v\n", err)
+			return
+		}
 	}
+}
 
-	AllArtists, _, _, _, err := cache.GetCachedData()
-	if err != nil {
-		makeAPIErrorResponse(w, http.StatusBadRequest, err.Error())
-		return
+// Apply returns the artists that match the filter request. With the `and` combinator, the artists must match
+// every given filter, otherwise, any of them. The result is in the order of the given artists for the `and`
+// combinator, and in the order of the filters otherwise.
+//
+// Returns ValidationErrors if the request is invalid.
+func Apply(artists []domain.Artist, requestData APIRequestData) ([]domain.Artist, error) {
+	if errs := Validate(requestData); len(errs) > 0 {
+		return nil, errs
 	}
 
+	AllArtists := artists
+
 	if requestData.Query != "" {
 		AllArtists = filterArtists(AllArtists, requestData.Query)
 	}
 
 	filteredArtistsIds := make(map[int]bool)
-	filteredArtists := make([]api.Artist, 0)
+	filteredArtists := make([]domain.Artist, 0)
 
 	// add the given artists to the list of filtered artists if they haven't been included yet
-	addArtists := func(artists []api.Artist) {
+	addArtists := func(artists []domain.Artist) {
 		for _, artist := range artists {
 			// add this artist if it's ID doesn't yet exist
 			_, ok := filteredArtistsIds[artist.ID]
@@ -127,8 +205,7 @@ func API(w http.ResponseWriter, r *http.Request) {
 	if requestData.CreationDateFilterQuery.Type != "" {
 		matchedArtists, err := filterByCreationDate(AllArtists, requestData.CreationDateFilterQuery)
 		if err != nil {
-			makeAPIErrorResponse(w, http.StatusBadRequest, "Invalid JSON for creation_date query")
-			return
+			return nil, fmt.Errorf("creation_date query: %w", err)
 		}
 
 		if isAnd {
@@ -142,8 +219,7 @@ func API(w http.ResponseWriter, r *http.Request) {
 	if requestData.FirstAlbumDateFilterQuery.Type != "" {
 		matchedArtists, err := filterByFirstAlbumDate(AllArtists, requestData.FirstAlbumDateFilterQuery)
 		if err != nil {
-			makeAPIErrorResponse(w, http.StatusBadRequest, "Invalid JSON for first_album_date query: "+err.Error())
-			return
+			return nil, fmt.Errorf("first_album_date query: %w", err)
 		}
 
 		if isAnd {
@@ -157,8 +233,7 @@ func API(w http.ResponseWriter, r *http.Request) {
 	if requestData.NumberOfMembersFilterQuery.Type != "" {
 		matchedArtists, err := filterByNumberOfMembers(AllArtists, requestData.NumberOfMembersFilterQuery)
 		if err != nil {
-			makeAPIErrorResponse(w, http.StatusBadRequest, "Invalid JSON for number_of_members query")
-			return
+			return nil, fmt.Errorf("number_of_members query: %w", err)
 		}
 
 		if isAnd {
@@ -172,8 +247,7 @@ func API(w http.ResponseWriter, r *http.Request) {
 	if len(requestData.LocationsOfConcertsFilterQuery.In) > 0 {
 		matchedArtists, err := filterByLocationsOfConcerts(AllArtists, requestData.LocationsOfConcertsFilterQuery)
 		if err != nil {
-			makeAPIErrorResponse(w, http.StatusBadRequest, "InternalServerError: Cache Map error")
-			return
+			return nil, fmt.Errorf("locations_of_concerts query: %w", err)
 		}
 
 		if isAnd {
@@ -183,31 +257,33 @@ func API(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if isAnd {
-		addArtists(AllArtists)
-	}
+	// Filter by geography
+	if !requestData.GeographyFilterQuery.IsZero() {
+		matchedArtists, err := filterByGeography(AllArtists, requestData.GeographyFilterQuery)
+		if err != nil {
+			return nil, fmt.Errorf("geography query: %w", err)
+		}
 
-	// Create a response
-	responseData := APIResponseData{
-		Status:  200,
-		Artists: filteredArtists,
+		if isAnd {
+			AllArtists = matchedArtists
+		} else {
+			addArtists(matchedArtists)
+		}
 	}
 
-	// Encode the response data as JSON and send it
-	err = json.NewEncoder(w).Encode(responseData)
-	if err != nil {
-		http.Error(w, "Error encoding response", http.StatusInternalServerError)
-		return
+	if isAnd {
+		addArtists(AllArtists)
 	}
+	return filteredArtists, nil
 }
 
-func filterByCreationDate(artists []api.Artist, q CreationDateFilterQuery) (result []api.Artist, err error) {
+func filterByCreationDate(artists []domain.Artist, q CreationDateFilterQuery) (result []domain.Artist, err error) {
 	if IsBlank(q.Type) {
 		return
 	}
 
 	if !slices.Contains([]string{"range", "in", "or"}, q.Type) {
-		return []api.Artist{}, errors.New("invalid query type")
+		return []domain.Artist{}, errors.New("invalid query type")
 	}
 
 	if IsBlank(q.Type) {
@@ -232,45 +308,95 @@ func filterByCreationDate(artists []api.Artist, q CreationDateFilterQuery) (resu
 	return
 }
 
-func filterByLocationsOfConcerts(artists []api.Artist, q LocationsOfConcertsFilterQuery) (
-	result []api.Artist, err error,
+func filterByLocationsOfConcerts(artists []domain.Artist, q LocationsOfConcertsFilterQuery) (
+	result []domain.Artist, err error,
 ) {
 	if len(q.In) == 0 {
 		return
 	}
 
-	locationsMap := cache.GetCachedLocationsMap()
-	if locationsMap == nil {
-		return nil, errors.New("locationsMap is nil")
+	var queries []location.Query
+	for _, in := range q.In {
+		queries = append(queries, location.ParseQuery(in))
 	}
 
 	for _, artist := range artists {
-		locations, ok := locationsMap[artist.ID]
+		if matchesLocations(artist.Locations, queries) {
+			result = append(result, artist)
+		}
+	}
+
+	return
+}
+
+// matchesLocations reports whether any of the given concert locations satisfies any of the queries
+func matchesLocations(locations []location.Place, queries []location.Query) bool {
+	for _, place := range locations {
+		for _, query := range queries {
+			if query.Matches(place) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// filterByGeography returns the artists with a concert location that satisfies the geography query.
+// Concert locations missing from the offline location dictionary never match.
+func filterByGeography(artists []domain.Artist, q GeographyFilterQuery) (
+	result []domain.Artist, err error,
+) {
+	var continentCodes []string
+	for _, continent := range q.Continents {
+		code, ok := location.Continent(continent)
 		if !ok {
-			log.Printf("artist with ID: %d has no concert location data in cache map", artist.ID)
-			continue
+			return nil, fmt.Errorf("unknown continent: %s", continent)
+		}
+		continentCodes = append(continentCodes, code)
+	}
+
+	var countryCodes []string
+	for _, code := range q.CountryCodes {
+		countryCodes = append(countryCodes, strings.ToUpper(strings.TrimSpace(code)))
+	}
+
+	var center location.Place
+	if q.Near != nil {
+		if q.Near.RadiusKm <= 0 {
+			return nil, fmt.Errorf("near radius_km must be positive: %v", q.Near.RadiusKm)
 		}
 
-		broke := false
-		for _, hyphenatedLocation := range locations {
-			loc := hyphenatedLocation
-			city, country := location.Parse(loc)
-			loc = fmt.Sprintf("%s, %s", city, country)
-			for _, in := range q.In {
-				// The user may have entered the location in the hyphenated location format
-				possibleHyphenatedLocation := in
-				city, country := location.GetCityCountry(in)
-				in = fmt.Sprintf("%s, %s", city, country)
-
-				if location.Contains(loc, in) || location.Contains(hyphenatedLocation, possibleHyphenatedLocation) {
-					result = append(result, artist)
-					// break from the outer loop as well
-					broke = true
-					break
-				}
+		if IsBlank(q.Near.Location) {
+			if q.Near.Lat < -90 || q.Near.Lat > 90 || q.Near.Lon < -180 || q.Near.Lon > 180 {
+				return nil, fmt.Errorf("invalid near coordinates: %v, %v", q.Near.Lat, q.Near.Lon)
+			}
+			center = location.Place{Lat: q.Near.Lat, Lon: q.Near.Lon}
+		} else {
+			var ok bool
+			center, ok = location.Find(q.Near.Location)
+			if !ok {
+				return nil, fmt.Errorf("unknown near location: %s", q.Near.Location)
 			}
-			// This artist has already been included in the result, other checks are unnecessary
-			if broke {
+		}
+	}
+
+	matches := func(place location.Place) bool {
+		if len(continentCodes) > 0 && !slices.Contains(continentCodes, place.Continent) {
+			return false
+		}
+		if len(countryCodes) > 0 && !slices.Contains(countryCodes, place.CountryCode) {
+			return false
+		}
+		if q.Near != nil && place.DistanceTo(center) > q.Near.RadiusKm {
+			return false
+		}
+		return true
+	}
+
+	for _, artist := range artists {
+		for _, place := range artist.Locations {
+			if place.Located() && matches(place) {
+				result = append(result, artist)
 				break
 			}
 		}
@@ -279,13 +405,13 @@ func filterByLocationsOfConcerts(artists []api.Artist, q LocationsOfConcertsFilt
 	return
 }
 
-func filterByNumberOfMembers(artists []api.Artist, q NumberOfMembersFilterQuery) (result []api.Artist, err error) {
+func filterByNumberOfMembers(artists []domain.Artist, q NumberOfMembersFilterQuery) (result []domain.Artist, err error) {
 	if IsBlank(q.Type) {
 		return
 	}
 
 	if !slices.Contains([]string{"range", "in", "or"}, q.Type) {
-		return []api.Artist{}, errors.New("invalid query type")
+		return []domain.Artist{}, errors.New("invalid query type")
 	}
 
 	if q.Type == "or" {
@@ -315,107 +441,79 @@ func filterByNumberOfMembers(artists []api.Artist, q NumberOfMembersFilterQuery)
 	return
 }
 
-func filterByFirstAlbumDate(artists []api.Artist, q FirstAlbumDateFilterQuery) (result []api.Artist, err error) {
+func filterByFirstAlbumDate(artists []domain.Artist, q FirstAlbumDateFilterQuery) (result []domain.Artist, err error) {
 	if IsBlank(q.Type) {
 		return
 	}
 
 	if !slices.Contains([]string{"range", "in", "or"}, q.Type) {
-		return []api.Artist{}, errors.New("invalid query type")
+		return []domain.Artist{}, errors.New("invalid query type")
 	}
 
-	compare := func(a time.Time, comparator string, b time.Time) bool {
-		if comparator == ">=" {
-			return a.Equal(b) || a.After(b)
-		} else if comparator == "<=" {
-			return a.Equal(b) || a.Before(b)
-		} else {
-			return false
+	// dates without a day or month, such as `1990`, cover their whole period
+	var qFrom, qTo xtime.Date
+	if q.Type == "range" || q.Type == "or" {
+		qFrom, err = xtime.ParseDate(q.From)
+		if err != nil {
+			return result, fmt.Errorf("invalid query from: %s", q.From)
 		}
-	}
-
-	for _, artist := range artists {
-		typeRange := func() error {
-			qFrom, err := xtime.Parse(q.From)
-			if err != nil {
-				return fmt.Errorf("invalid query from: %s", q.From)
-			}
-
-			qTo, err := xtime.Parse(q.To)
-			if err != nil {
-				return fmt.Errorf("invalid query to: %s", q.To)
-			}
 
-			artistFirstAlbumDate, err := xtime.Parse(artist.FirstAlbum)
-			if err != nil {
-				return fmt.Errorf("invalid first album date format: %v, for artist: %s", artist.FirstAlbum, artist.Name)
-			}
-
-			if compare(artistFirstAlbumDate, ">=", qFrom) &&
-				compare(artistFirstAlbumDate, "<=", qTo) {
-				result = append(result, artist)
-			}
-
-			return nil
+		qTo, err = xtime.ParseDate(q.To)
+		if err != nil {
+			return result, fmt.Errorf("invalid query to: %s", q.To)
 		}
+	}
 
-		typeIn := func() error {
-			artistFirstAlbumDate, err := xtime.Parse(artist.FirstAlbum)
+	var qIn []xtime.Date
+	if q.Type == "in" || q.Type == "or" {
+		for _, in := range q.In {
+			currentIn, err := xtime.ParseDate(in)
 			if err != nil {
-				return fmt.Errorf("invalid first album date format: %v, for artist: %s", artist.FirstAlbum, artist.Name)
+				return result, err
 			}
 
-			var qIn []time.Time
-			for _, in := range q.In {
-				currentIn, err := xtime.Parse(in)
-				if err != nil {
-					return err
-				}
+			qIn = append(qIn, currentIn)
+		}
+	}
 
-				qIn = append(qIn, currentIn)
-			}
+	inRange := func(t time.Time) bool {
+		return !t.Before(qFrom.Start()) && !t.After(qTo.End())
+	}
 
-			if slices.Contains(qIn, artistFirstAlbumDate) {
-				result = append(result, artist)
-			}
+	isIn := func(t time.Time) bool {
+		return slices.ContainsFunc(
+			qIn, func(in xtime.Date) bool {
+				return in.Contains(t)
+			},
+		)
+	}
 
-			return nil
+	for _, artist := range artists {
+		// artists with an unknown first album date never match
+		if artist.FirstAlbum.IsZero() {
+			continue
 		}
 
-		if q.Type == "range" {
-			err := typeRange()
-			if err != nil {
-				return result, err
-			}
-		} else if q.Type == "in" {
-			err := typeIn()
-			if err != nil {
-				return result, err
-			}
-		} else if q.Type == "or" {
-			err := typeRange()
-			if err != nil {
-				return result, err
-			}
-
-			err = typeIn()
-			if err != nil {
-				return result, err
-			}
+		if (q.Type == "range" && inRange(artist.FirstAlbum)) ||
+			(q.Type == "in" && isIn(artist.FirstAlbum)) ||
+			(q.Type == "or" && (inRange(artist.FirstAlbum) || isIn(artist.FirstAlbum))) {
+			result = append(result, artist)
 		}
 	}
 
 	return
 }
 
-// filterArtists filters the list of artists based on the search query
-func filterArtists(artists []api.Artist, query string) []api.Artist {
+// filterArtists filters the list of artists based on the search query.
+// The query matches the artists' concert locations component by component.
+func filterArtists(artists []domain.Artist, query string) []domain.Artist {
 	if query == "" {
 		return artists
 	}
 
+	locationQuery := []location.Query{location.ParseQuery(query)}
 	query = strings.ToLower(query)
-	var result []api.Artist
+	var result []domain.Artist
 
 	for _, a := range artists {
 		// Artist/band name matches
@@ -433,7 +531,7 @@ func filterArtists(artists []api.Artist, query string) []api.Artist {
 		}
 
 		// First album dates
-		if strings.Contains(strings.ToLower(a.FirstAlbum), query) {
+		if strings.Contains(a.FirstAlbum.Format("02-01-2006"), query) {
 			result = append(result, a)
 			continue
 		}
@@ -445,7 +543,7 @@ func filterArtists(artists []api.Artist, query string) []api.Artist {
 		}
 
 		// locations
-		if strings.Contains(strings.ToLower(a.Locations), strings.ToLower(query)) {
+		if matchesLocations(a.Locations, locationQuery) {
 			result = append(result, a)
 			continue
 		}
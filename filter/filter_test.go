@@ -3,18 +3,112 @@ package filter
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"groupie-tracker/api"
+	"groupie-tracker/domain"
+	"groupie-tracker/httperr"
+	"groupie-tracker/location"
+	"groupie-tracker/xerrors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
+// fakeStore serves the artists of a snapshot, or fails if err is set
+type fakeStore struct {
+	snapshot *domain.Snapshot
+	err      error
+}
+
+func (s fakeStore) Snapshot() (*domain.Snapshot, error) {
+	return s.snapshot, s.err
+}
+
+// errorPage renders every template as an error page
+type errorPage struct{}
+
+func (errorPage) Execute(w io.Writer, _ string, _ any) error {
+	_, err := io.WriteString(w, "<h1>error page</h1>")
+	return err
+}
+
+// newFakeStore returns a store of the artists of the TestAPI cases, each with its concert locations
+func newFakeStore(t *testing.T) fakeStore {
+	t.Helper()
+	fixtures := []struct {
+		name         string
+		creationDate int
+		firstAlbum   string
+		members      int
+		locations    []string
+	}{
+		{"SOJA", 1997, "01-05-2000", 8, []string{"new_york-usa"}},
+		{"Mamonas Assassinas", 1995, "01-01-1995", 5, []string{"sao_paulo-brazil"}},
+		{"Thirty Seconds to Mars", 1998, "27-08-2002", 3, []string{"seattle-usa", "los_angeles-usa"}},
+		{"Nickelback", 1995, "01-01-1996", 4, []string{"toronto-canada"}},
+		{"NWA", 1996, "01-01-1997", 5, []string{"los_angeles-usa"}},
+		{"Gorillaz", 1998, "26-03-2001", 4, []string{"london-uk"}},
+		{"Linkin Park", 1996, "24-10-2000", 6, []string{"los_angeles-usa", "berlin-germany"}},
+		{"Eminem", 1996, "12-11-1996", 1, []string{"new_york-usa"}},
+		{"Coldplay", 1996, "10-07-2000", 5, []string{"london-uk", "paris-france"}},
+		{"Pearl Jam", 1990, "27-08-1991", 5, []string{"toronto-canada"}},
+		{"Red Hot Chili Peppers", 1983, "01-06-1990", 4, []string{"los_angeles-usa"}},
+		{"Pink Floyd", 1965, "05-08-1967", 6, []string{"london-uk"}},
+		{"Arctic Monkeys", 2002, "23-01-2006", 6, []string{"paris-france"}},
+		{"Foo Fighters", 1994, "04-07-1995", 6, []string{"berlin-germany"}},
+		{"R3HAB", 2008, "01-01-2012", 1, []string{"dallas-usa"}},
+		{"Logic", 2009, "01-01-2014", 1, []string{"houston-usa"}},
+		{"Joyner Lucas", 2007, "01-01-2015", 1, []string{"texas-usa"}},
+		{"Twenty One Pilots", 2009, "01-01-2009", 2, []string{"austin-usa", "toronto-canada"}},
+		{"Bobby McFerrins", 1977, "01-01-1982", 1, []string{"new_york-usa"}},
+		{"XXXTentacion", 2013, "25-08-2017", 1, []string{"los_angeles-usa"}},
+		{"Juice Wrld", 2015, "23-05-2018", 1, []string{"new_york-usa"}},
+		{"Alec Benjamin", 2013, "16-11-2018", 1, []string{"paris-france"}},
+		{"Post Malone", 2013, "09-12-2016", 1, []string{"sao_paulo-brazil"}},
+		{"The Rolling Stones", 1962, "16-04-1964", 4, []string{"seattle-usa", "london-uk"}},
+		{"Phil Collins", 1969, "13-02-1981", 1, []string{"berlin-germany"}},
+		{"Metallica", 1981, "25-07-1983", 4, []string{"toronto-canada"}},
+		{"Queen", 1970, "14-12-1973", 4, []string{"london-uk"}},
+	}
+
+	var artists []api.Artist
+	var relations []api.Relations
+	for i, fixture := range fixtures {
+		id := i + 1
+		members := make([]string, fixture.members)
+		for j := range members {
+			members[j] = fmt.Sprintf("Member %d", j+1)
+		}
+		artists = append(
+			artists, api.Artist{
+				ID: id, Name: fixture.name, Members: members,
+				CreationDate: fixture.creationDate, FirstAlbum: fixture.firstAlbum,
+			},
+		)
+		dates := make(map[string][]string, len(fixture.locations))
+		for _, slug := range fixture.locations {
+			dates[slug] = []string{"01-06-2019"}
+		}
+		relations = append(relations, api.Relations{Id: id, DatesLocation: dates})
+	}
+
+	fetchedAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	snapshot, err := domain.NewSnapshot(artists, nil, relations, fetchedAt)
+	if err != nil {
+		t.Fatalf("NewSnapshot() error = %v", err)
+	}
+	return fakeStore{snapshot: snapshot}
+}
+
 func TestAPI(t *testing.T) {
 	tests := []struct {
 		name       string
 		request    APIRequestData
 		expected   []string
 		statusCode int
-		method     int
 	}{
 		{
 			name: "Filter artists between creation dates 1995 and 2000",
@@ -134,28 +228,9 @@ func TestAPI(t *testing.T) {
 			expected:   []string{"Phil Collins", "Bobby McFerrins", "Red Hot Chili Peppers", "Metallica"},
 			statusCode: http.StatusOK,
 		},
-		{
-			name: "Method Not Allowed: Filter artists by first albums between 1980 and 1990 with max 4 members",
-			request: APIRequestData{
-				FirstAlbumDateFilterQuery: FirstAlbumDateFilterQuery{
-					From: "01-01-1980",
-					To:   "31-12-1990",
-					Type: "range",
-				},
-				NumberOfMembersFilterQuery: NumberOfMembersFilterQuery{
-					From: 0,
-					To:   4,
-					Type: "range",
-				},
-				Combinator: "and",
-			},
-			expected:   []string{},
-			statusCode: http.StatusMethodNotAllowed,
-			method:     1,
-		},
 	}
 
-	handler := http.HandlerFunc(API)
+	handler := API(newFakeStore(t), httperr.NewWriter(errorPage{}))
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			// Marshal the request payload to JSON
@@ -164,13 +239,8 @@ func TestAPI(t *testing.T) {
 				t.Fatalf("Failed to marshal request payload: %v", err)
 			}
 
-			Method := http.MethodPost
-			if tc.method == 1 {
-				Method = http.MethodGet
-			}
-
 			// Create a new HTTP POST request with the JSON payload
-			req, err := http.NewRequest(Method, "/api/filter", bytes.NewBuffer(payload))
+			req, err := http.NewRequest(http.MethodPost, "/api/filter", bytes.NewBuffer(payload))
 			if err != nil {
 				t.Fatalf("Failed to create HTTP request: %v", err)
 			}
@@ -213,6 +283,71 @@ func TestAPI(t *testing.T) {
 	}
 }
 
+func TestAPIErrors(t *testing.T) {
+	store := newFakeStore(t)
+	unavailable := fakeStore{err: fmt.Errorf("%w: the artists are being fetched", xerrors.ErrUnavailable)}
+
+	tests := []struct {
+		name       string
+		store      Store
+		body       string
+		wantStatus int
+		wantErrors bool
+	}{
+		{
+			name:       "Malformed JSON",
+			store:      store,
+			body:       `{"combinator":`,
+			wantStatus: http.StatusBadRequest,
+			wantErrors: true,
+		},
+		{
+			name:       "Unknown country code",
+			store:      store,
+			body:       `{"geography":{"country_codes":["ZZ"]}}`,
+			wantStatus: http.StatusBadRequest,
+			wantErrors: true,
+		},
+		{
+			name:       "Body too large",
+			store:      store,
+			body:       `{"combinator":"` + strings.Repeat("a", maxRequestSize) + `"}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{name: "Store unavailable", store: unavailable, body: `{}`, wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range tests {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				handler := API(tc.store, httperr.NewWriter(errorPage{}))
+				req := httptest.NewRequest(http.MethodPost, "/api/filter", strings.NewReader(tc.body))
+				req.Header.Set("Content-Type", "application/json")
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+
+				if rec.Code != tc.wantStatus {
+					t.Errorf("status = %d, want %d", rec.Code, tc.wantStatus)
+				}
+				if got := rec.Header().Get("Content-Type"); got != "application/problem+json" {
+					t.Errorf("Content-Type = %q, want application/problem+json", got)
+				}
+
+				var problem httperr.Problem
+				if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+					t.Fatalf("Failed to unmarshal problem: %v", err)
+				}
+				if problem.Status != tc.wantStatus || problem.Instance != "/api/filter" {
+					t.Errorf("problem = %+v, want status %d on /api/filter", problem, tc.wantStatus)
+				}
+				if (problem.Errors != nil) != tc.wantErrors {
+					t.Errorf("problem errors = %v, want errors %t", problem.Errors, tc.wantErrors)
+				}
+			},
+		)
+	}
+}
+
 // slicesEqual checks if two slices of strings contain exactly the same elements,
 // not necessarily in the same order
 func slicesEqual(s1, s2 []string) bool {
@@ -247,3 +382,95 @@ func slicesEqual(s1, s2 []string) bool {
 
 	return true
 }
+
+func TestFilterByGeography(t *testing.T) {
+	places := func(slugs ...string) (result []location.Place) {
+		for _, slug := range slugs {
+			result = append(result, location.PlaceOf(slug))
+		}
+		return
+	}
+	artists := []domain.Artist{
+		{ID: 1, Name: "Queen", Locations: places("osaka-japan", "leipzig-germany")},
+		{ID: 2, Name: "SOJA", Locations: places("texas-usa", "rio_de_janeiro-brazil")},
+		{ID: 3, Name: "Pink Floyd", Locations: places("london-uk", "atlantis-ocean")},
+	}
+
+	tests := []struct {
+		name     string
+		query    GeographyFilterQuery
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "Continent by name",
+			query:    GeographyFilterQuery{Continents: []string{"Europe"}},
+			expected: []string{"Queen", "Pink Floyd"},
+		},
+		{
+			name:     "Continent by code",
+			query:    GeographyFilterQuery{Continents: []string{"sa", "AS"}},
+			expected: []string{"Queen", "SOJA"},
+		},
+		{
+			name:     "Country code",
+			query:    GeographyFilterQuery{CountryCodes: []string{"us"}},
+			expected: []string{"SOJA"},
+		},
+		{
+			name:     "Within 300 km of Berlin",
+			query:    GeographyFilterQuery{Near: &NearFilterQuery{Location: "Berlin", RadiusKm: 300}},
+			expected: []string{"Queen"},
+		},
+		{
+			name:     "Within 100 km of coordinates",
+			query:    GeographyFilterQuery{Near: &NearFilterQuery{Lat: 51.5, Lon: 0, RadiusKm: 100}},
+			expected: []string{"Pink Floyd"},
+		},
+		{
+			name: "Every criteria must hold for the same concert",
+			query: GeographyFilterQuery{
+				Continents: []string{"Asia"},
+				Near:       &NearFilterQuery{Location: "berlin-germany", RadiusKm: 300},
+			},
+			expected: []string{},
+		},
+		{
+			name:    "Unknown continent",
+			query:   GeographyFilterQuery{Continents: []string{"Atlantis"}},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown near location",
+			query:   GeographyFilterQuery{Near: &NearFilterQuery{Location: "Atlantis", RadiusKm: 300}},
+			wantErr: true,
+		},
+		{
+			name:    "Non positive radius",
+			query:   GeographyFilterQuery{Near: &NearFilterQuery{Location: "Berlin"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				result, err := filterByGeography(artists, tc.query)
+				if (err != nil) != tc.wantErr {
+					t.Fatalf("filterByGeography() error = %v, wantErr %v", err, tc.wantErr)
+				}
+				if tc.wantErr {
+					return
+				}
+
+				var names []string
+				for _, artist := range result {
+					names = append(names, artist.Name)
+				}
+				if !slicesEqual(tc.expected, names) {
+					t.Errorf("Expected artists %v, but got %v", tc.expected, names)
+				}
+			},
+		)
+	}
+}
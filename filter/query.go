@@ -0,0 +1,187 @@
+package filter
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// The filter requests are encoded in URL query strings with one parameter per field, named after the path of
+// the field in the JSON request, e.g. `creation_date.from=1990`. Array fields are repeated parameters, and zero
+// values are left out.
+const (
+	paramQuery      = "query"
+	paramCombinator = "combinator"
+
+	paramCreationDate        = "creation_date"
+	paramFirstAlbumDate      = "first_album_date"
+	paramNumberOfMembers     = "number_of_members"
+	paramLocationsOfConcerts = "locations_of_concerts"
+	paramGeography           = "geography"
+)
+
+// EncodeQuery returns the URL query parameters of the filter request. DecodeQuery decodes them back into an
+// equal request.
+func EncodeQuery(q APIRequestData) url.Values {
+	values := url.Values{}
+	setString(values, paramQuery, q.Query)
+	setString(values, paramCombinator, q.Combinator)
+
+	encodeNumberQuery(values, paramCreationDate, q.CreationDateFilterQuery)
+	encodeNumberQuery(values, paramNumberOfMembers, q.NumberOfMembersFilterQuery)
+
+	firstAlbum := q.FirstAlbumDateFilterQuery
+	setString(values, paramFirstAlbumDate+".type", firstAlbum.Type)
+	setString(values, paramFirstAlbumDate+".from", firstAlbum.From)
+	setString(values, paramFirstAlbumDate+".to", firstAlbum.To)
+	for _, date := range firstAlbum.In {
+		values.Add(paramFirstAlbumDate+".in", date)
+	}
+
+	for _, loc := range q.LocationsOfConcertsFilterQuery.In {
+		values.Add(paramLocationsOfConcerts+".in", loc)
+	}
+
+	geography := q.GeographyFilterQuery
+	for _, continent := range geography.Continents {
+		values.Add(paramGeography+".continents", continent)
+	}
+	for _, code := range geography.CountryCodes {
+		values.Add(paramGeography+".country_codes", code)
+	}
+	if near := geography.Near; near != nil {
+		setString(values, paramGeography+".near.location", near.Location)
+		setFloat(values, paramGeography+".near.lat", near.Lat)
+		setFloat(values, paramGeography+".near.lon", near.Lon)
+		// the radius is always set, so that a near query with a zero radius is still decoded
+		values.Set(paramGeography+".near.radius_km", strconv.FormatFloat(near.RadiusKm, 'f', -1, 64))
+	}
+	return values
+}
+
+// DecodeQuery returns the filter request encoded in the URL query parameters by EncodeQuery, or submitted by
+// the filter form. Unknown parameters are ignored. Returns an error naming the first parameter whose value
+// isn't a valid number.
+func DecodeQuery(values url.Values) (q APIRequestData, err error) {
+	q.Query = values.Get(paramQuery)
+	q.Combinator = values.Get(paramCombinator)
+
+	if q.CreationDateFilterQuery, err = decodeNumberQuery(values, paramCreationDate); err != nil {
+		return APIRequestData{}, err
+	}
+	if q.NumberOfMembersFilterQuery, err = decodeNumberQuery(values, paramNumberOfMembers); err != nil {
+		return APIRequestData{}, err
+	}
+
+	q.FirstAlbumDateFilterQuery = FirstAlbumDateFilterQuery{
+		Type: values.Get(paramFirstAlbumDate + ".type"),
+		From: values.Get(paramFirstAlbumDate + ".from"),
+		To:   values.Get(paramFirstAlbumDate + ".to"),
+		In:   values[paramFirstAlbumDate+".in"],
+	}
+
+	// a value may hold several `;` separated locations, as typed in the filter form
+	for _, value := range values[paramLocationsOfConcerts+".in"] {
+		for _, loc := range strings.Split(value, ";") {
+			if loc = strings.TrimSpace(loc); loc != "" {
+				q.LocationsOfConcertsFilterQuery.In = append(q.LocationsOfConcertsFilterQuery.In, loc)
+			}
+		}
+	}
+
+	q.GeographyFilterQuery.Continents = values[paramGeography+".continents"]
+	q.GeographyFilterQuery.CountryCodes = values[paramGeography+".country_codes"]
+	for _, field := range []string{"location", "lat", "lon", "radius_km"} {
+		if values.Has(paramGeography + ".near." + field) {
+			q.GeographyFilterQuery.Near = &NearFilterQuery{}
+			break
+		}
+	}
+	if near := q.GeographyFilterQuery.Near; near != nil {
+		near.Location = values.Get(paramGeography + ".near.location")
+		if near.Lat, err = getFloat(values, paramGeography+".near.lat"); err != nil {
+			return APIRequestData{}, err
+		}
+		if near.Lon, err = getFloat(values, paramGeography+".near.lon"); err != nil {
+			return APIRequestData{}, err
+		}
+		if near.RadiusKm, err = getFloat(values, paramGeography+".near.radius_km"); err != nil {
+			return APIRequestData{}, err
+		}
+	}
+	return q, nil
+}
+
+// encodeNumberQuery sets the parameters of the number query, named after the prefix
+func encodeNumberQuery(values url.Values, prefix string, q NumberOfMembersFilterQuery) {
+	setString(values, prefix+".type", q.Type)
+	if q.From != 0 {
+		values.Set(prefix+".from", strconv.Itoa(q.From))
+	}
+	if q.To != 0 {
+		values.Set(prefix+".to", strconv.Itoa(q.To))
+	}
+	for _, n := range q.In {
+		values.Add(prefix+".in", strconv.Itoa(n))
+	}
+}
+
+// decodeNumberQuery returns the number query encoded in the parameters named after the prefix
+func decodeNumberQuery(values url.Values, prefix string) (q NumberOfMembersFilterQuery, err error) {
+	q.Type = values.Get(prefix + ".type")
+	if q.From, err = getInt(values, prefix+".from"); err != nil {
+		return NumberOfMembersFilterQuery{}, err
+	}
+	if q.To, err = getInt(values, prefix+".to"); err != nil {
+		return NumberOfMembersFilterQuery{}, err
+	}
+	for _, s := range values[prefix+".in"] {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return NumberOfMembersFilterQuery{}, fmt.Errorf("invalid %s: %q is not a whole number", prefix+".in", s)
+		}
+		q.In = append(q.In, n)
+	}
+	return q, nil
+}
+
+// setString sets the parameter to s, unless s is blank
+func setString(values url.Values, param, s string) {
+	if s != "" {
+		values.Set(param, s)
+	}
+}
+
+// setFloat sets the parameter to f, unless f is zero
+func setFloat(values url.Values, param string, f float64) {
+	if f != 0 {
+		values.Set(param, strconv.FormatFloat(f, 'f', -1, 64))
+	}
+}
+
+// getInt returns the whole number value of the parameter, or zero if it isn't set
+func getInt(values url.Values, param string) (int, error) {
+	s := values.Get(param)
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %q is not a whole number", param, s)
+	}
+	return n, nil
+}
+
+// getFloat returns the number value of the parameter, or zero if it isn't set
+func getFloat(values url.Values, param string) (float64, error) {
+	s := values.Get(param)
+	if s == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %q is not a number", param, s)
+	}
+	return f, nil
+}
@@ -0,0 +1,126 @@
+package filter
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestQueryRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		request APIRequestData
+		encoded string
+	}{
+		{
+			name:    "Empty",
+			request: APIRequestData{},
+			encoded: "",
+		},
+		{
+			name: "Search and creation date range",
+			request: APIRequestData{
+				CreationDateFilterQuery: CreationDateFilterQuery{From: 1990, To: 2000, Type: "range"},
+				Combinator:              "and",
+				Query:                   "queen & co",
+			},
+			encoded: "combinator=and&creation_date.from=1990&creation_date.to=2000&creation_date.type=range" +
+				"&query=queen+%26+co",
+		},
+		{
+			name: "Members, first album and locations",
+			request: APIRequestData{
+				FirstAlbumDateFilterQuery: FirstAlbumDateFilterQuery{
+					From: "01-01-1990", To: "31-12-1999", In: []string{"2005"}, Type: "or",
+				},
+				LocationsOfConcertsFilterQuery: LocationsOfConcertsFilterQuery{In: []string{"Texas, USA", "london-uk"}},
+				NumberOfMembersFilterQuery:     NumberOfMembersFilterQuery{In: []int{1, 4}, Type: "in"},
+			},
+			encoded: "first_album_date.from=01-01-1990&first_album_date.in=2005&first_album_date.to=31-12-1999" +
+				"&first_album_date.type=or&locations_of_concerts.in=Texas%2C+USA&locations_of_concerts.in=london-uk" +
+				"&number_of_members.in=1&number_of_members.in=4&number_of_members.type=in",
+		},
+		{
+			name: "Geography",
+			request: APIRequestData{
+				GeographyFilterQuery: GeographyFilterQuery{
+					Continents:   []string{"Europe"},
+					CountryCodes: []string{"US", "GB"},
+					Near:         &NearFilterQuery{Lat: 51.5, Lon: -0.12, RadiusKm: 250.5},
+				},
+			},
+			encoded: "geography.continents=Europe&geography.country_codes=US&geography.country_codes=GB" +
+				"&geography.near.lat=51.5&geography.near.lon=-0.12&geography.near.radius_km=250.5",
+		},
+		{
+			name: "Near a location with a zero radius",
+			request: APIRequestData{
+				GeographyFilterQuery: GeographyFilterQuery{Near: &NearFilterQuery{Location: "Berlin"}},
+			},
+			encoded: "geography.near.location=Berlin&geography.near.radius_km=0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				values := EncodeQuery(tt.request)
+				if got := values.Encode(); got != tt.encoded {
+					t.Errorf("EncodeQuery() = %s, want %s", got, tt.encoded)
+				}
+
+				decoded, err := DecodeQuery(values)
+				if err != nil {
+					t.Fatalf("DecodeQuery() error = %v", err)
+				}
+				if !reflect.DeepEqual(decoded, tt.request) {
+					t.Errorf("DecodeQuery() = %+v, want %+v", decoded, tt.request)
+				}
+			},
+		)
+	}
+}
+
+func TestDecodeQueryForm(t *testing.T) {
+	// the filter form submits the concert locations as a single `;` separated value
+	values, _ := url.ParseQuery(
+		"query=queen&combinator=and&number_of_members.type=in&number_of_members.in=2&number_of_members.in=4" +
+			"&locations_of_concerts.in=Nairobi%2C+Kenya%3B+Washington%2C+USA%3B+",
+	)
+
+	got, err := DecodeQuery(values)
+	if err != nil {
+		t.Fatalf("DecodeQuery() error = %v", err)
+	}
+	want := APIRequestData{
+		LocationsOfConcertsFilterQuery: LocationsOfConcertsFilterQuery{In: []string{"Nairobi, Kenya", "Washington, USA"}},
+		NumberOfMembersFilterQuery:     NumberOfMembersFilterQuery{In: []int{2, 4}, Type: "in"},
+		Combinator:                     "and",
+		Query:                          "queen",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeQuery() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeQueryInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "Creation date", query: "creation_date.from=nineteen"},
+		{name: "Members", query: "number_of_members.in=1&number_of_members.in=two"},
+		{name: "Radius", query: "geography.near.location=Berlin&geography.near.radius_km=far"},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				values, _ := url.ParseQuery(tt.query)
+				if _, err := DecodeQuery(values); err == nil {
+					t.Errorf("DecodeQuery(%s) error = nil, want an error", tt.query)
+				}
+			},
+		)
+	}
+}
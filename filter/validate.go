@@ -0,0 +1,348 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"groupie-tracker/location"
+	"groupie-tracker/xerrors"
+	"groupie-tracker/xtime"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// The codes of the validation errors
+const (
+	CodeInvalidJSON     = "invalid_json"
+	CodeUnknownField    = "unknown_field"
+	CodeInvalidType     = "invalid_type"
+	CodeInvalidValue    = "invalid_value"
+	CodeInvalidDate     = "invalid_date"
+	CodeRangeInverted   = "range_inverted"
+	CodeOutOfRange      = "out_of_range"
+	CodeRequired        = "required"
+	CodeUnknownLocation = "unknown_location"
+)
+
+// queryTypes are the valid filter query types, the blank type disables the filter
+var queryTypes = []string{"", "range", "in", "or"}
+
+// ValidationError describes an invalid field of a filter request
+type ValidationError struct {
+	// Field is the path of the field in the JSON request, e.g. `creation_date.from` or `geography.continents[1]`.
+	// It is blank for errors about the whole request.
+	Field string `json:"field"`
+	// Code identifies the kind of error, one of the Code constants
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors lists the invalid fields of a filter request
+type ValidationErrors []ValidationError
+
+// Unwrap returns xerrors.ErrBadRequest, as validation errors are client errors
+func (errs ValidationErrors) Unwrap() error {
+	return xerrors.ErrBadRequest
+}
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// add appends a validation error for the field
+func (errs *ValidationErrors) add(field, code, format string, args ...any) {
+	*errs = append(*errs, ValidationError{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// maxRequestSize is the size in bytes of the largest filter request body, see limitBody
+const maxRequestSize = 1 << 20
+
+// limitBody limits the body of the request to maxRequestSize bytes, see DecodeRequest
+func limitBody(w http.ResponseWriter, r *http.Request) io.Reader {
+	return http.MaxBytesReader(w, r.Body, maxRequestSize)
+}
+
+// DecodeRequest reads the JSON filter request from r, rejecting unknown fields, and validates it.
+// Returns ValidationErrors if the request is malformed or invalid, or an error wrapping xerrors.ErrTooLarge
+// if r is limited by http.MaxBytesReader, e.g. by limitBody, and holds more bytes than allowed.
+func DecodeRequest(r io.Reader) (APIRequestData, error) {
+	body, err := io.ReadAll(r)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return APIRequestData{}, fmt.Errorf(
+			"%w: the request body must not be larger than %d bytes", xerrors.ErrTooLarge, tooLarge.Limit,
+		)
+	}
+	if err != nil {
+		return APIRequestData{}, ValidationErrors{
+			{Code: CodeInvalidJSON, Message: "the request body can't be read: " + err.Error()},
+		}
+	}
+
+	var requestData APIRequestData
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&requestData); err != nil {
+		return APIRequestData{}, ValidationErrors{decodeError(body, err)}
+	}
+	if decoder.More() {
+		return APIRequestData{}, ValidationErrors{
+			{Code: CodeInvalidJSON, Message: "the request body must hold a single JSON object"},
+		}
+	}
+
+	if errs := Validate(requestData); len(errs) > 0 {
+		return APIRequestData{}, errs
+	}
+	return requestData, nil
+}
+
+// decodeError returns the validation error describing the error decoding the JSON request body
+func decodeError(body []byte, err error) ValidationError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return ValidationError{
+			Field:   typeErr.Field,
+			Code:    CodeInvalidType,
+			Message: fmt.Sprintf("expected %s, got JSON %s", typeErr.Type, typeErr.Value),
+		}
+	}
+
+	// the json package reports unknown fields without their path, so the request is searched for them
+	var value any
+	if json.NewDecoder(bytes.NewReader(body)).Decode(&value) == nil {
+		if field, ok := unknownField(value, reflect.TypeOf(APIRequestData{}), ""); ok {
+			return ValidationError{Field: field, Code: CodeUnknownField, Message: "unknown field"}
+		}
+	}
+
+	if errors.Is(err, io.EOF) {
+		return ValidationError{Code: CodeInvalidJSON, Message: "the request body is empty"}
+	}
+	return ValidationError{Code: CodeInvalidJSON, Message: "invalid JSON: " + err.Error()}
+}
+
+// unknownField returns the path of the first field of the decoded JSON value that the type t has no field for,
+// e.g. `creation_date.until`, in alphabetical order at each level. The object keys are matched with the struct
+// fields as by the json package: by their JSON name, ignoring case. Returns false if every field is known.
+func unknownField(value any, t reflect.Type, path string) (string, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		if t.Kind() != reflect.Struct {
+			return "", false
+		}
+		fields := jsonFields(t)
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			field := key
+			if path != "" {
+				field = path + "." + key
+			}
+			fieldType, ok := fieldOf(fields, key)
+			if !ok {
+				return field, true
+			}
+			if name, ok := unknownField(value[key], fieldType, field); ok {
+				return name, true
+			}
+		}
+	case []any:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return "", false
+		}
+		for i, elem := range value {
+			if name, ok := unknownField(elem, t.Elem(), fmt.Sprintf("%s[%d]", path, i)); ok {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// jsonFields returns the types of the JSON fields of the struct type, by JSON name, including the fields of
+// its embedded structs without a JSON name
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			for embeddedName, embeddedType := range jsonFields(fieldType) {
+				if _, ok := fields[embeddedName]; !ok {
+					fields[embeddedName] = embeddedType
+				}
+			}
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// fieldOf returns the type of the JSON field with the given name, matched exactly, or otherwise ignoring case
+func fieldOf(fields map[string]reflect.Type, name string) (reflect.Type, bool) {
+	if t, ok := fields[name]; ok {
+		return t, true
+	}
+	for fieldName, t := range fields {
+		if strings.EqualFold(fieldName, name) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// Validate returns the errors of the filter request: unknown query types and combinators, inverted ranges,
+// unparseable dates, and unknown or out of range geography criteria. Returns nil if the request is valid.
+func Validate(q APIRequestData) ValidationErrors {
+	var errs ValidationErrors
+
+	switch strings.ToLower(strings.TrimSpace(q.Combinator)) {
+	case "", "and", "or":
+	default:
+		errs.add("combinator", CodeInvalidValue, "must be `and` or `or`, got %q", q.Combinator)
+	}
+
+	validateNumberQuery(&errs, "creation_date", q.CreationDateFilterQuery)
+	validateNumberQuery(&errs, "number_of_members", q.NumberOfMembersFilterQuery)
+	validateFirstAlbumDateQuery(&errs, q.FirstAlbumDateFilterQuery)
+
+	for i, query := range q.LocationsOfConcertsFilterQuery.In {
+		if location.ParseQuery(query).IsZero() {
+			errs.add(fmt.Sprintf("locations_of_concerts.in[%d]", i), CodeRequired, "must not be blank")
+		}
+	}
+
+	validateGeographyQuery(&errs, q.GeographyFilterQuery)
+	return errs
+}
+
+// validateNumberQuery appends the errors of the number query, whose JSON field is field
+func validateNumberQuery(errs *ValidationErrors, field string, q NumberOfMembersFilterQuery) {
+	if !validateQueryType(errs, field, q.Type) {
+		return
+	}
+
+	if (q.Type == "range" || q.Type == "or") && q.From > q.To {
+		errs.add(field+".from", CodeRangeInverted, "must not be greater than to (%d), got %d", q.To, q.From)
+	}
+}
+
+// validateFirstAlbumDateQuery appends the errors of the first album date query
+func validateFirstAlbumDateQuery(errs *ValidationErrors, q FirstAlbumDateFilterQuery) {
+	const field = "first_album_date"
+	if !validateQueryType(errs, field, q.Type) {
+		return
+	}
+
+	if q.Type == "range" || q.Type == "or" {
+		from, fromErr := xtime.ParseDate(q.From)
+		if fromErr != nil {
+			errs.add(field+".from", CodeInvalidDate, "%v", fromErr)
+		}
+		to, toErr := xtime.ParseDate(q.To)
+		if toErr != nil {
+			errs.add(field+".to", CodeInvalidDate, "%v", toErr)
+		}
+		if fromErr == nil && toErr == nil && from.Start().After(to.End()) {
+			errs.add(field+".from", CodeRangeInverted, "must not be after to (%s), got %s", q.To, q.From)
+		}
+	}
+
+	if q.Type == "in" || q.Type == "or" {
+		for i, in := range q.In {
+			if _, err := xtime.ParseDate(in); err != nil {
+				errs.add(fmt.Sprintf("%s.in[%d]", field, i), CodeInvalidDate, "%v", err)
+			}
+		}
+	}
+}
+
+// validateQueryType appends an error if the query type is unknown. Returns true if the type is valid, and
+// not blank, that is, if the rest of the query should be validated.
+func validateQueryType(errs *ValidationErrors, field, queryType string) bool {
+	for _, valid := range queryTypes {
+		if queryType == valid {
+			return queryType != ""
+		}
+	}
+	errs.add(field+".type", CodeInvalidValue, "must be one of `range`, `in` or `or`, got %q", queryType)
+	return false
+}
+
+// validateGeographyQuery appends the errors of the geography query
+func validateGeographyQuery(errs *ValidationErrors, q GeographyFilterQuery) {
+	for i, continent := range q.Continents {
+		if _, ok := location.Continent(continent); !ok {
+			errs.add(fmt.Sprintf("geography.continents[%d]", i), CodeInvalidValue, "unknown continent %q", continent)
+		}
+	}
+
+	for i, code := range q.CountryCodes {
+		if len(strings.TrimSpace(code)) != 2 {
+			errs.add(
+				fmt.Sprintf("geography.country_codes[%d]", i), CodeInvalidValue,
+				"must be a 2 letter ISO 3166 country code, got %q", code,
+			)
+		} else if _, ok := location.CountryName(code); !ok {
+			errs.add(fmt.Sprintf("geography.country_codes[%d]", i), CodeInvalidValue, "unknown country code %q", code)
+		}
+	}
+
+	near := q.Near
+	if near == nil {
+		return
+	}
+	if near.RadiusKm <= 0 {
+		errs.add("geography.near.radius_km", CodeOutOfRange, "must be positive, got %v", near.RadiusKm)
+	}
+	if !IsBlank(near.Location) {
+		if _, ok := location.Find(near.Location); !ok {
+			errs.add("geography.near.location", CodeUnknownLocation, "unknown location %q", near.Location)
+		}
+		return
+	}
+	if near.Lat < -90 || near.Lat > 90 {
+		errs.add("geography.near.lat", CodeOutOfRange, "must be between -90 and 90, got %v", near.Lat)
+	}
+	if near.Lon < -180 || near.Lon > 180 {
+		errs.add("geography.near.lon", CodeOutOfRange, "must be between -180 and 180, got %v", near.Lon)
+	}
+}
@@ -0,0 +1,162 @@
+package filter
+
+import (
+	"errors"
+	"groupie-tracker/httperr"
+	"groupie-tracker/xerrors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		// want lists the expected errors as `field:code`, none if the request is valid
+		want []string
+	}{
+		{
+			name: "Valid request",
+			body: `{"creation_date": {"from": 1990, "to": 2000, "type": "range"}, "combinator": "and"}`,
+		},
+		{
+			name: "Valid request with a disabled filter",
+			body: `{"number_of_members": {"from": 9, "to": 1, "type": ""}, "combinator": "OR"}`,
+		},
+		{
+			name: "Empty body",
+			body: ``,
+			want: []string{":invalid_json"},
+		},
+		{
+			name: "Malformed JSON",
+			body: `{"combinator": "and"`,
+			want: []string{":invalid_json"},
+		},
+		{
+			name: "Trailing data",
+			body: `{"combinator": "and"} {}`,
+			want: []string{":invalid_json"},
+		},
+		{
+			name: "Unknown top level field",
+			body: `{"combinater": "and"}`,
+			want: []string{"combinater:unknown_field"},
+		},
+		{
+			name: "Unknown nested field",
+			body: `{"creation_date": {"from": 1990, "until": 2000, "type": "range"}}`,
+			want: []string{"creation_date.until:unknown_field"},
+		},
+		{
+			name: "Unknown deeply nested field",
+			body: `{"geography": {"near": {"location": "london", "radius": 10}}}`,
+			want: []string{"geography.near.radius:unknown_field"},
+		},
+		{
+			name: "Field name in another case",
+			body: `{"Combinator": "and", "creation_date": {"From": 1990, "to": 2000, "type": "range"}}`,
+		},
+		{
+			name: "Wrong type",
+			body: `{"creation_date": {"from": "1990", "to": 2000, "type": "range"}}`,
+			want: []string{"creation_date.from:invalid_type"},
+		},
+		{
+			name: "Unknown combinator",
+			body: `{"combinator": "xor"}`,
+			want: []string{"combinator:invalid_value"},
+		},
+		{
+			name: "Inverted ranges",
+			body: `{"creation_date": {"from": 2000, "to": 1990, "type": "range"},
+				"number_of_members": {"from": 5, "to": 2, "in": [1], "type": "or"},
+				"first_album_date": {"from": "2000", "to": "31-12-1999", "type": "range"}}`,
+			want: []string{
+				"creation_date.from:range_inverted",
+				"number_of_members.from:range_inverted",
+				"first_album_date.from:range_inverted",
+			},
+		},
+		{
+			name: "Same year range is not inverted",
+			body: `{"first_album_date": {"from": "1999", "to": "1999", "type": "range"}}`,
+		},
+		{
+			name: "Unknown query type",
+			body: `{"number_of_members": {"in": [1], "type": "exactly"}}`,
+			want: []string{"number_of_members.type:invalid_value"},
+		},
+		{
+			name: "Invalid dates",
+			body: `{"first_album_date": {"from": "someday", "to": "32-01-1999", "in": ["1990", "never"], "type": "or"}}`,
+			want: []string{
+				"first_album_date.from:invalid_date",
+				"first_album_date.to:invalid_date",
+				"first_album_date.in[1]:invalid_date",
+			},
+		},
+		{
+			name: "Blank location",
+			body: `{"locations_of_concerts": {"in": ["Texas, USA", "  "]}}`,
+			want: []string{"locations_of_concerts.in[1]:required"},
+		},
+		{
+			name: "Invalid geography",
+			body: `{"geography": {"continents": ["Europe", "Atlantis"], "country_codes": ["USA", "de", "ZZ"],
+				"near": {"location": "Atlantis", "radius_km": 0}}}`,
+			want: []string{
+				"geography.continents[1]:invalid_value",
+				"geography.country_codes[0]:invalid_value",
+				"geography.country_codes[2]:invalid_value",
+				"geography.near.radius_km:out_of_range",
+				"geography.near.location:unknown_location",
+			},
+		},
+		{
+			name: "Invalid coordinates",
+			body: `{"geography": {"near": {"lat": 91, "lon": -181, "radius_km": 10}}}`,
+			want: []string{"geography.near.lat:out_of_range", "geography.near.lon:out_of_range"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := DecodeRequest(strings.NewReader(tt.body))
+
+				var got []string
+				var errs ValidationErrors
+				if errors.As(err, &errs) {
+					for _, e := range errs {
+						if e.Message == "" {
+							t.Errorf("error %s:%s has no message", e.Field, e.Code)
+						}
+						got = append(got, e.Field+":"+e.Code)
+					}
+				} else if err != nil {
+					t.Fatalf("DecodeRequest() error = %v, want ValidationErrors", err)
+				}
+
+				if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+					t.Errorf("DecodeRequest() errors = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestDecodeRequestTooLarge(t *testing.T) {
+	body := `{"combinator": "and", "members": {"in": ["` + strings.Repeat("1", maxRequestSize) + `"]}}`
+	r := httptest.NewRequest("POST", "/api/filter", strings.NewReader(body))
+
+	_, err := DecodeRequest(limitBody(httptest.NewRecorder(), r))
+	if !errors.Is(err, xerrors.ErrTooLarge) {
+		t.Errorf("DecodeRequest() error = %v, want ErrTooLarge", err)
+	}
+	if status := httperr.StatusOf(err); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("status of DecodeRequest() error = %d, want %d", status, http.StatusRequestEntityTooLarge)
+	}
+}
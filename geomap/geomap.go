@@ -0,0 +1,114 @@
+// Package geomap projects concert locations onto an offline SVG world map
+package geomap
+
+import (
+	"fmt"
+	"groupie-tracker/domain"
+	"strings"
+	"time"
+)
+
+const (
+	// Width of the map in SVG user units
+	Width = 1000
+	// Height of the map in SVG user units
+	Height = 400
+
+	// the map is cropped to the latitudes between maxLat and minLat, leaving out the poles
+	maxLat = 84.0
+	minLat = -60.0
+)
+
+// Stop is a concert location plotted on the map
+type Stop struct {
+	X     float64
+	Y     float64
+	Label string
+	// Dates of the concerts held at this location, in chronological order
+	Dates []time.Time
+}
+
+// Map is an equirectangular projection of an artist's concert locations, ready to be rendered as SVG
+type Map struct {
+	Width  int
+	Height int
+	// Land holds the SVG path data of the continent outlines
+	Land  []string
+	Stops []Stop
+	// Route holds the SVG polyline points connecting the stops in chronological order
+	Route string
+	// Unplaced lists the concert locations that have no known coordinates
+	Unplaced []string
+}
+
+// New returns the map of the given concerts, which must be in chronological order
+func New(concerts []domain.Concert) Map {
+	m := Map{Width: Width, Height: Height, Land: landPaths()}
+
+	// index of each location's stop, or -1 if it can't be placed on the map
+	stops := make(map[string]int)
+	var points []string
+	last := -1
+	for _, concert := range concerts {
+		slug := concert.Place.Slug
+		i, ok := stops[slug]
+		if !ok {
+			i = -1
+			if concert.Place.Located() {
+				x, y := project(concert.Place.Lon, concert.Place.Lat)
+				i = len(m.Stops)
+				m.Stops = append(m.Stops, Stop{X: x, Y: y, Label: concert.Place.String()})
+			} else {
+				m.Unplaced = append(m.Unplaced, slug)
+			}
+			stops[slug] = i
+		}
+		if i < 0 {
+			continue
+		}
+
+		m.Stops[i].Dates = append(m.Stops[i].Dates, concert.Date)
+		// consecutive shows at the same stop don't move the route
+		if i != last {
+			points = append(points, formatPoint(m.Stops[i].X, m.Stops[i].Y))
+			last = i
+		}
+	}
+
+	if len(points) > 1 {
+		m.Route = strings.Join(points, " ")
+	}
+
+	return m
+}
+
+// project returns the map coordinates of the given longitude and latitude
+func project(lon, lat float64) (x, y float64) {
+	x = (lon + 180) / 360 * Width
+	y = (maxLat - lat) / (maxLat - minLat) * Height
+	return
+}
+
+// formatPoint formats the map coordinates as an SVG point
+func formatPoint(x, y float64) string {
+	return fmt.Sprintf("%.1f,%.1f", x, y)
+}
+
+// landPaths returns the SVG path data of each land outline
+func landPaths() []string {
+	paths := make([]string, 0, len(land))
+	for _, outline := range land {
+		var d strings.Builder
+		for i, point := range outline {
+			command := "L"
+			if i == 0 {
+				command = "M"
+			}
+			x, y := project(point[0], point[1])
+			d.WriteString(command + formatPoint(x, y))
+		}
+		d.WriteString("Z")
+		paths = append(paths, d.String())
+	}
+	return paths
+}
@@ -0,0 +1,77 @@
+package geomap
+
+import (
+	"groupie-tracker/domain"
+	"groupie-tracker/location"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProject(t *testing.T) {
+	tests := []struct {
+		name  string
+		lon   float64
+		lat   float64
+		wantX float64
+		wantY float64
+	}{
+		{name: "Top left", lon: -180, lat: 84, wantX: 0, wantY: 0},
+		{name: "Bottom right", lon: 180, lat: -60, wantX: Width, wantY: Height},
+		{name: "Equator, prime meridian", lon: 0, lat: 0, wantX: Width / 2, wantY: 84.0 / 144 * Height},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				x, y := project(tt.lon, tt.lat)
+				if x != tt.wantX || y != tt.wantY {
+					t.Errorf("project() = (%v, %v), want (%v, %v)", x, y, tt.wantX, tt.wantY)
+				}
+			},
+		)
+	}
+}
+
+func TestNew(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2020, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	concert := func(slug string, d int) domain.Concert {
+		return domain.Concert{Place: location.PlaceOf(slug), Date: day(d)}
+	}
+
+	m := New(
+		[]domain.Concert{
+			concert("berlin-germany", 1),
+			concert("atlantis-ocean", 2),
+			concert("osaka-japan", 28),
+			concert("osaka-japan", 30),
+			concert("london-uk", 31),
+		},
+	)
+
+	if !reflect.DeepEqual(m.Unplaced, []string{"atlantis-ocean"}) {
+		t.Errorf("New() Unplaced = %v, want [atlantis-ocean]", m.Unplaced)
+	}
+
+	if len(m.Stops) != 3 {
+		t.Fatalf("New() got %d stops, want 3", len(m.Stops))
+	}
+
+	// stops are in the order they are first visited: berlin, osaka, london
+	osaka := m.Stops[1]
+	if osaka.Label != "Osaka, Japan" || !reflect.DeepEqual(osaka.Dates, []time.Time{day(28), day(30)}) {
+		t.Errorf("New() osaka stop = %+v", osaka)
+	}
+
+	// the two osaka shows are a single leg
+	var want []string
+	for _, stop := range m.Stops {
+		want = append(want, formatPoint(stop.X, stop.Y))
+	}
+	if m.Route != strings.Join(want, " ") {
+		t.Errorf("New() Route = %q, want %q", m.Route, strings.Join(want, " "))
+	}
+}
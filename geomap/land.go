@@ -0,0 +1,78 @@
+package geomap
+
+// land holds coarse outlines of the continents and major islands as (longitude, latitude) pairs.
+// They are only meant to give the concert stops a recognisable backdrop, not to be geographically accurate.
+var land = [][][2]float64{
+	// North America
+	{
+		{-168, 66}, {-162, 70}, {-140, 70}, {-125, 70}, {-95, 72}, {-80, 73}, {-62, 67}, {-55, 52}, {-66, 45},
+		{-70, 42}, {-76, 35}, {-81, 31}, {-80, 25}, {-82, 29}, {-84, 30}, {-90, 29}, {-97, 27}, {-97, 22},
+		{-92, 18}, {-87, 21}, {-88, 16}, {-83, 15}, {-83, 10}, {-79, 9}, {-77, 8}, {-80, 8}, {-86, 12},
+		{-92, 15}, {-96, 16}, {-105, 20}, {-106, 23}, {-112, 29}, {-115, 32}, {-117, 32}, {-120, 34},
+		{-124, 40}, {-124, 48}, {-130, 55}, {-140, 60}, {-150, 60}, {-160, 58}, {-165, 62},
+	},
+	// Greenland
+	{{-73, 78}, {-60, 82}, {-30, 83}, {-20, 80}, {-20, 70}, {-42, 60}, {-50, 64}, {-55, 70}, {-68, 76}},
+	// South America
+	{
+		{-77, 8}, {-72, 12}, {-62, 11}, {-52, 5}, {-50, 0}, {-35, -5}, {-35, -9}, {-39, -14}, {-41, -22},
+		{-48, -26}, {-53, -34}, {-58, -38}, {-62, -40}, {-65, -45}, {-68, -51}, {-70, -55}, {-74, -52},
+		{-73, -45}, {-73, -37}, {-71, -30}, {-70, -18}, {-76, -14}, {-81, -5}, {-80, 0}, {-78, 3},
+	},
+	// Europe
+	{
+		{-10, 36}, {-9, 43}, {-2, 43}, {-1, 46}, {-5, 48}, {2, 51}, {5, 53}, {8, 54}, {8, 57}, {10, 59}, {5, 59},
+		{5, 62}, {14, 68}, {20, 70}, {28, 71}, {40, 68}, {44, 68}, {60, 69}, {60, 55}, {50, 45}, {40, 44},
+		{36, 41}, {29, 41}, {26, 40}, {23, 37}, {21, 40}, {19, 42}, {16, 39}, {16, 38}, {12, 44}, {9, 44},
+		{3, 43}, {0, 39}, {-2, 37}, {-6, 36},
+	},
+	// Great Britain
+	{{-5, 50}, {1, 51}, {2, 53}, {0, 54}, {-2, 57}, {-2, 59}, {-6, 58}, {-5, 55}, {-3, 54}, {-5, 52}},
+	// Ireland
+	{{-10, 52}, {-6, 52}, {-6, 55}, {-8, 55}, {-10, 54}},
+	// Iceland
+	{{-24, 65}, {-14, 64}, {-14, 66}, {-22, 66}},
+	// Africa
+	{
+		{-17, 21}, {-13, 28}, {-10, 30}, {-6, 36}, {10, 37}, {11, 33}, {20, 31}, {32, 31}, {34, 28}, {43, 12},
+		{51, 12}, {51, 10}, {40, -3}, {40, -15}, {35, -24}, {32, -29}, {27, -34}, {20, -35}, {18, -32},
+		{12, -18}, {13, -12}, {9, -1}, {9, 4}, {3, 6}, {-8, 4}, {-13, 8}, {-17, 14},
+	},
+	// Madagascar
+	{{44, -25}, {47, -25}, {50, -15}, {49, -12}, {44, -17}},
+	// Asia
+	{
+		{26, 40}, {36, 36}, {35, 32}, {34, 28}, {43, 13}, {45, 13}, {52, 16}, {57, 19}, {59, 23}, {56, 26},
+		{51, 24}, {48, 29}, {50, 30}, {56, 27}, {62, 25}, {67, 24}, {73, 21}, {77, 8}, {80, 13}, {80, 16},
+		{87, 21}, {92, 22}, {94, 17}, {98, 16}, {99, 9}, {101, 3}, {104, 1}, {103, 5}, {100, 13}, {105, 9},
+		{109, 12}, {108, 17}, {106, 20}, {110, 21}, {117, 23}, {121, 28}, {122, 31}, {119, 35}, {122, 37},
+		{118, 38}, {122, 40}, {126, 38}, {129, 35}, {129, 40}, {135, 43}, {140, 48}, {142, 54}, {136, 55},
+		{140, 60}, {156, 61}, {162, 60}, {163, 68}, {180, 67}, {180, 71}, {160, 70}, {140, 72}, {113, 74},
+		{105, 78}, {90, 76}, {75, 72}, {68, 69}, {60, 69}, {60, 55}, {50, 45}, {40, 44}, {36, 41}, {29, 41},
+	},
+	// Japan
+	{
+		{130, 31}, {132, 34}, {135, 34}, {140, 35}, {142, 39}, {141, 42}, {145, 44}, {142, 45}, {140, 42},
+		{139, 38}, {136, 37}, {133, 35}, {130, 33},
+	},
+	// Philippines
+	{{120, 18}, {122, 18}, {126, 7}, {122, 7}, {120, 14}},
+	// Sumatra
+	{{95, 5}, {106, -6}, {104, -5}, {98, 0}},
+	// Java
+	{{105, -6}, {114, -7}, {114, -8}, {106, -7}},
+	// Borneo
+	{{109, 1}, {117, 7}, {119, 5}, {116, -4}, {110, -3}},
+	// New Guinea
+	{{131, -1}, {141, -3}, {150, -10}, {141, -9}, {138, -8}},
+	// Australia
+	{
+		{114, -22}, {114, -34}, {118, -35}, {124, -34}, {131, -31}, {138, -35}, {141, -38}, {146, -39},
+		{150, -37}, {153, -32}, {153, -25}, {146, -19}, {142, -11}, {136, -12}, {136, -15}, {130, -12},
+		{125, -14}, {122, -18},
+	},
+	// New Zealand, North Island
+	{{173, -35}, {178, -38}, {175, -41}, {172, -40}, {174, -37}},
+	// New Zealand, South Island
+	{{172, -41}, {174, -42}, {171, -44}, {169, -46}, {166, -46}, {168, -44}},
+}
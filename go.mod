@@ -1,3 +1,3 @@
 module groupie-tracker
 
-go 1.19
+go 1.22
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"groupie-tracker/domain"
+	"groupie-tracker/httperr"
+	"groupie-tracker/xerrors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	// DefaultOverlapWindow is the number of days apart two concerts in the same city may be held, for the
+	// artists to have played alongside each other, unless another window is requested
+	DefaultOverlapWindow = 3
+	// maxOverlapWindow is the largest window of days that can be requested
+	maxOverlapWindow = 31
+)
+
+// artistResource responds with a resource of an artist, at /api/v1/artists/{id}/{resource}. It returns the error
+// of an invalid request instead of responding, see ArtistAPIHandler.
+type artistResource func(
+	w http.ResponseWriter, r *http.Request, snapshot *domain.Snapshot, artist domain.Artist,
+) error
+
+// artistResources maps the resource names of the artist API to their handlers
+var artistResources = map[string]artistResource{
+	"overlaps": artistOverlaps,
+	"tour":     artistTour,
+}
+
+// ArtistAPIHandler handles HTTP GET requests for the resources of an artist, at /api/v1/artists/{id}/{resource}.
+// It responds with RFC 9457 problem details on error.
+//
+// The resources are:
+//   - overlaps: the artists who played in the same city on the same date or within a window of days,
+//     e.g. at the same festival. The window defaults to DefaultOverlapWindow days, and can be set with
+//     the `window` query parameter, from 0 (same date only) to 31.
+//   - tour: the metrics of the artist's concerts: the number of concerts, cities and countries, the first and
+//     last concerts, the longest gap between concerts, and the distance travelled from concert to concert.
+func (app *App) ArtistAPIHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	resource, ok := artistResources[r.PathValue("resource")]
+	if !ok {
+		app.errors.Write(w, r, httperr.JSON, httperr.New(http.StatusNotFound, ""))
+		return
+	}
+
+	snapshot, err := app.store.Snapshot()
+	if err != nil {
+		app.errors.Error(w, r, httperr.JSON, err)
+		return
+	}
+
+	ID, err := strconv.Atoi(id)
+	artist, found := snapshot.Artist(ID)
+	if err != nil || !found {
+		app.errors.Write(w, r, httperr.JSON, httperr.New(http.StatusNotFound, fmt.Sprintf("no artist with id %q", id)))
+		return
+	}
+
+	if err := resource(w, r, snapshot, artist); err != nil {
+		app.errors.Error(w, r, httperr.JSON, err)
+	}
+}
+
+// OverlapsResponse is the payload of the overlaps resource of the artist API
+type OverlapsResponse struct {
+	ArtistID   int                     `json:"artistId"`
+	WindowDays int                     `json:"windowDays"`
+	Artists    []domain.ArtistOverlaps `json:"artists"`
+}
+
+// artistOverlaps responds with the artists who played alongside the artist
+func artistOverlaps(w http.ResponseWriter, r *http.Request, snapshot *domain.Snapshot, artist domain.Artist) error {
+	window, err := parseOverlapWindow(r.URL.Query())
+	if err != nil {
+		return err
+	}
+
+	overlaps, _ := snapshot.Overlaps(artist.ID, window)
+	if overlaps == nil {
+		overlaps = []domain.ArtistOverlaps{}
+	}
+	writeJSON(w, OverlapsResponse{ArtistID: artist.ID, WindowDays: window, Artists: overlaps})
+	return nil
+}
+
+// artistTour responds with the metrics of the artist's concerts
+func artistTour(w http.ResponseWriter, _ *http.Request, _ *domain.Snapshot, artist domain.Artist) error {
+	writeJSON(w, artist.Tour())
+	return nil
+}
+
+// parseOverlapWindow returns the window of days of the `window` query parameter, DefaultOverlapWindow if unset
+func parseOverlapWindow(query url.Values) (int, error) {
+	value := query.Get("window")
+	if value == "" {
+		return DefaultOverlapWindow, nil
+	}
+
+	window, err := strconv.Atoi(value)
+	if err != nil || window < 0 || window > maxOverlapWindow {
+		return 0, fmt.Errorf(
+			"%w: window must be a number of days from 0 to %d, got %q", xerrors.ErrBadRequest, maxOverlapWindow, value,
+		)
+	}
+	return window, nil
+}
+
+// writeJSON responds with the JSON encoding of v
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error encoding response: %v\n", err)
+	}
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"encoding/json"
+	"groupie-tracker/httperr"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseOverlapWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    int
+		wantErr bool
+	}{
+		{
+			name:  "Default",
+			query: "",
+			want:  DefaultOverlapWindow,
+		},
+		{
+			name:  "Same date only",
+			query: "window=0",
+			want:  0,
+		},
+		{
+			name:  "Largest window",
+			query: "window=31",
+			want:  31,
+		},
+		{
+			name:    "Negative",
+			query:   "window=-1",
+			wantErr: true,
+		},
+		{
+			name:    "Too large",
+			query:   "window=32",
+			wantErr: true,
+		},
+		{
+			name:    "Not a number",
+			query:   "window=week",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				query, err := url.ParseQuery(tt.query)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got, err := parseOverlapWindow(query)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("parseOverlapWindow() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if got != tt.want {
+					t.Errorf("parseOverlapWindow() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestArtistAPIHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		id           string
+		resource     string
+		expectedCode int
+	}{
+		{
+			name:         "Unknown resource",
+			method:       "GET",
+			target:       "/api/v1/artists/1/albums",
+			id:           "1",
+			resource:     "albums",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	app := newTestApp(t)
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(tt.method, tt.target, nil)
+				req.SetPathValue("id", tt.id)
+				req.SetPathValue("resource", tt.resource)
+				rr := httptest.NewRecorder()
+
+				app.ArtistAPIHandler(rr, req)
+
+				if rr.Code != tt.expectedCode {
+					t.Errorf("ArtistAPIHandler() status = %v, want %v", rr.Code, tt.expectedCode)
+				}
+				if got := rr.Header().Get("Content-Type"); got != httperr.ProblemContentType {
+					t.Errorf("ArtistAPIHandler() Content-Type = %q, want %q", got, httperr.ProblemContentType)
+				}
+			},
+		)
+	}
+}
+
+func TestArtistOverlapsAPI(t *testing.T) {
+	tests := []struct {
+		name         string
+		target       string
+		expectedCode int
+		wantWindow   int
+		// wantArtists are the IDs of the artists who played alongside Queen
+		wantArtists []int
+	}{
+		{
+			name:         "Default window",
+			target:       "/api/v1/artists/1/overlaps",
+			expectedCode: http.StatusOK,
+			wantWindow:   DefaultOverlapWindow,
+			wantArtists:  []int{2},
+		},
+		{
+			name:         "Window of the days apart",
+			target:       "/api/v1/artists/1/overlaps?window=2",
+			expectedCode: http.StatusOK,
+			wantWindow:   2,
+			wantArtists:  []int{2},
+		},
+		{
+			name:         "Same date only",
+			target:       "/api/v1/artists/1/overlaps?window=0",
+			expectedCode: http.StatusOK,
+			wantWindow:   0,
+			wantArtists:  []int{},
+		},
+		{
+			name:         "Invalid window",
+			target:       "/api/v1/artists/1/overlaps?window=32",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	app := newTestApp(t)
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequest("GET", tt.target, nil)
+				req.SetPathValue("id", "1")
+				req.SetPathValue("resource", "overlaps")
+				rr := httptest.NewRecorder()
+
+				app.ArtistAPIHandler(rr, req)
+
+				if rr.Code != tt.expectedCode {
+					t.Fatalf("ArtistAPIHandler() status = %v, want %v", rr.Code, tt.expectedCode)
+				}
+				if tt.expectedCode != http.StatusOK {
+					return
+				}
+
+				var response struct {
+					ArtistID   int `json:"artistId"`
+					WindowDays int `json:"windowDays"`
+					Artists    []struct {
+						ID       int `json:"id"`
+						Overlaps []struct {
+							Place struct {
+								Slug string `json:"slug"`
+							} `json:"place"`
+							Date      string `json:"date"`
+							OtherDate string `json:"otherDate"`
+							DaysApart int    `json:"daysApart"`
+						} `json:"overlaps"`
+					} `json:"artists"`
+				}
+				if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+					t.Fatalf("ArtistAPIHandler() body is not an overlaps object: %v", err)
+				}
+				if response.ArtistID != 1 || response.WindowDays != tt.wantWindow {
+					t.Errorf(
+						"ArtistAPIHandler() artistId, windowDays = %d, %d, want 1, %d",
+						response.ArtistID, response.WindowDays, tt.wantWindow,
+					)
+				}
+
+				ids := []int{}
+				for _, artist := range response.Artists {
+					ids = append(ids, artist.ID)
+				}
+				if !reflect.DeepEqual(ids, tt.wantArtists) {
+					t.Fatalf("ArtistAPIHandler() artists = %v, want %v", ids, tt.wantArtists)
+				}
+				for _, artist := range response.Artists {
+					if len(artist.Overlaps) != 1 {
+						t.Fatalf("ArtistAPIHandler() overlaps = %+v, want a single one", artist.Overlaps)
+					}
+					overlap := artist.Overlaps[0]
+					if overlap.Place.Slug != "london-uk" || overlap.Date != "2019-12-31" ||
+						overlap.OtherDate != "2019-12-29" || overlap.DaysApart != 2 {
+						t.Errorf("ArtistAPIHandler() overlap = %+v, want one in london-uk, 2 days apart", overlap)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestArtistTourAPI(t *testing.T) {
+	app := newTestApp(t)
+	req := httptest.NewRequest("GET", "/api/v1/artists/1/tour", nil)
+	req.SetPathValue("id", "1")
+	req.SetPathValue("resource", "tour")
+	rr := httptest.NewRecorder()
+
+	app.ArtistAPIHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("ArtistAPIHandler() status = %v, want %v", rr.Code, http.StatusOK)
+	}
+
+	var tour struct {
+		Concerts   int `json:"concerts"`
+		Cities     int `json:"cities"`
+		Countries  int `json:"countries"`
+		LongestGap struct {
+			From struct {
+				Date string `json:"date"`
+			} `json:"from"`
+			To struct {
+				Date string `json:"date"`
+			} `json:"to"`
+			Days int `json:"days"`
+		} `json:"longestGap"`
+		DistanceKm float64 `json:"distanceKm"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &tour); err != nil {
+		t.Fatalf("ArtistAPIHandler() body is not a tour object: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{name: "concerts", got: tour.Concerts, want: 2},
+		{name: "cities", got: tour.Cities, want: 2},
+		{name: "countries", got: tour.Countries, want: 2},
+		{name: "longestGap.from", got: tour.LongestGap.From.Date, want: "2019-12-31"},
+		{name: "longestGap.to", got: tour.LongestGap.To.Date, want: "2020-02-01"},
+		{name: "longestGap.days", got: tour.LongestGap.Days, want: 32},
+		// the great-circle distance from London to Dallas
+		{name: "distanceKm", got: tour.DistanceKm, want: 7641.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if tt.got != tt.want {
+					t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"fmt"
+	"groupie-tracker/domain"
+	"groupie-tracker/ical"
+	"groupie-tracker/location"
+	"groupie-tracker/xtime"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// calendarFileName is the file name of the iCalendar feeds
+const calendarFileName = "concerts.ics"
+
+// ArtistCalendarHandler handles HTTP GET requests for the iCalendar feed of an artist's concerts,
+// at /artists/{artist}/concerts.ics, where {artist} is the artist's ID and slug, see DetailsHandler.
+// Requests for the artist's ID with another slug, or at the former path, /details/{id}/concerts.ics,
+// are redirected to the feed's path.
+//
+// Every concert is an all-day event, located at the normalized name of its location.
+//
+// The handler returns appropriate HTTP status codes:
+//   - 200 OK: Successfully written the calendar
+//   - 301 Moved Permanently: The feed's path differs from the requested path
+//   - 404 Not Found: Invalid or non-existent artist ID
+//   - 500 Internal Server Error: Server-side processing errors
+func (app *App) ArtistCalendarHandler(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := app.store.Snapshot()
+	if err != nil {
+		app.renderError(w, r, err)
+		return
+	}
+
+	artist, found := artistOf(snapshot, r.PathValue("artist"))
+	if !found {
+		app.RenderErrorPage(w, r, "The artist id entered is out of range!", http.StatusNotFound)
+		return
+	}
+	if path := artistPath(artist) + "/" + calendarFileName; r.URL.Path != path {
+		redirect(w, r, path)
+		return
+	}
+
+	calendar := ical.Calendar{Name: artist.Name + " concerts", Stamp: snapshot.FetchedAt}
+	for _, concert := range artist.Concerts {
+		calendar.Events = append(calendar.Events, concertEvent(app.baseURL, artist, concert))
+	}
+	writeCalendar(w, calendar, fmt.Sprintf("artist-%d-%s", artist.ID, calendarFileName))
+}
+
+// CalendarHandler handles HTTP GET requests for the iCalendar feed of all artists' concerts, at /concerts.ics.
+//
+// The concerts can be narrowed down with the query parameters, which may all be repeated:
+//   - artist: the ID of an artist
+//   - location: a location query, as accepted by location.ParseQuery, e.g. `texas` or `country:usa`
+//   - continent: a continent name or code, e.g. `europe` or `EU`
+//   - from, to: the first and last concert dates, in any form accepted by xtime.ParseDate, e.g. `2019` or `2019-06`
+//
+// A concert is included if it matches any of the values of every given parameter.
+func (app *App) CalendarHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseCalendarFilter(r.URL.Query())
+	if err != nil {
+		app.RenderErrorPage(w, r, "Bad Request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	snapshot, err := app.store.Snapshot()
+	if err != nil {
+		app.renderError(w, r, err)
+		return
+	}
+
+	calendar := ical.Calendar{Name: "Groupie Tracker concerts", Stamp: snapshot.FetchedAt}
+	for _, artist := range snapshot.Artists {
+		if !filter.matchesArtist(artist) {
+			continue
+		}
+		for _, concert := range artist.Concerts {
+			if filter.matchesConcert(concert) {
+				calendar.Events = append(calendar.Events, concertEvent(app.baseURL, artist, concert))
+			}
+		}
+	}
+	writeCalendar(w, calendar, calendarFileName)
+}
+
+// calendarFilter narrows down the concerts of the all-artists calendar feed
+type calendarFilter struct {
+	artistIDs  []int
+	locations  []location.Query
+	continents []string
+	from, to   *xtime.Date
+}
+
+// parseCalendarFilter returns the filter described by the query parameters of the all-artists calendar feed
+func parseCalendarFilter(query url.Values) (calendarFilter, error) {
+	var filter calendarFilter
+	for _, value := range query["artist"] {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return calendarFilter{}, fmt.Errorf("invalid artist ID %q", value)
+		}
+		filter.artistIDs = append(filter.artistIDs, id)
+	}
+
+	for _, value := range query["location"] {
+		if q := location.ParseQuery(value); !q.IsZero() {
+			filter.locations = append(filter.locations, q)
+		}
+	}
+
+	for _, value := range query["continent"] {
+		code, ok := location.Continent(value)
+		if !ok {
+			return calendarFilter{}, fmt.Errorf("unknown continent %q", value)
+		}
+		filter.continents = append(filter.continents, code)
+	}
+
+	for _, bound := range []struct {
+		name string
+		date **xtime.Date
+	}{{"from", &filter.from}, {"to", &filter.to}} {
+		value := query.Get(bound.name)
+		if value == "" {
+			continue
+		}
+		date, err := xtime.ParseDate(value)
+		if err != nil {
+			return calendarFilter{}, fmt.Errorf("invalid %s date: %w", bound.name, err)
+		}
+		*bound.date = &date
+	}
+	return filter, nil
+}
+
+// matchesArtist reports whether the artist is one of the filtered artists
+func (f calendarFilter) matchesArtist(artist domain.Artist) bool {
+	if len(f.artistIDs) == 0 {
+		return true
+	}
+	for _, id := range f.artistIDs {
+		if artist.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesConcert reports whether the concert is held at one of the filtered locations and continents,
+// between the from and to dates
+func (f calendarFilter) matchesConcert(concert domain.Concert) bool {
+	if f.from != nil && concert.Date.Before(f.from.Start()) {
+		return false
+	}
+	if f.to != nil && concert.Date.After(f.to.End()) {
+		return false
+	}
+
+	if len(f.continents) > 0 {
+		found := false
+		for _, code := range f.continents {
+			found = found || concert.Place.Continent == code
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(f.locations) > 0 {
+		found := false
+		for _, q := range f.locations {
+			found = found || q.Matches(concert.Place)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// concertEvent returns the calendar event of the artist's concert. Its UID is derived from the artist ID,
+// date and location, so that it stays the same across calendar refreshes. The event links to the artist's page
+// on the site at baseURL, unless it is empty.
+func concertEvent(baseURL string, artist domain.Artist, concert domain.Concert) ical.Event {
+	place := concert.Place
+	name := place.City
+	if name == "" {
+		name = place.String()
+	}
+
+	event := ical.Event{
+		UID:      fmt.Sprintf("%d-%s-%s@groupie-tracker", artist.ID, concert.Date.Format("20060102"), place.Slug),
+		Date:     concert.Date,
+		Summary:  fmt.Sprintf("%s in %s", artist.Name, name),
+		Location: place.String(),
+		Lat:      place.Lat,
+		Lon:      place.Lon,
+		HasGeo:   place.Located(),
+	}
+	if baseURL != "" {
+		event.URL = baseURL + artistPath(artist)
+	}
+	return event
+}
+
+// writeCalendar writes the calendar as an iCalendar file with the given name
+func writeCalendar(w http.ResponseWriter, calendar ical.Calendar, fileName string) {
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", fileName))
+	if err := calendar.Encode(w); err != nil {
+		log.Printf("Error writing calendar: %v\n", err)
+	}
+}
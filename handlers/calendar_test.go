@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"groupie-tracker/domain"
+	"groupie-tracker/location"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendarFilter(t *testing.T) {
+	london, _ := location.Normalize("london-uk")
+	dallas, _ := location.Normalize("dallas-usa")
+	queen := domain.Artist{ID: 1, Name: "Queen"}
+	concertIn := func(place location.Place, year int, month time.Month, day int) domain.Concert {
+		return domain.Concert{Place: place, Date: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		wantErr     bool
+		wantArtist  bool
+		wantConcert map[string]bool
+	}{
+		{
+			name:       "No filter",
+			query:      "",
+			wantArtist: true,
+			wantConcert: map[string]bool{
+				"london": true,
+				"dallas": true,
+			},
+		},
+		{
+			name:       "Other artist",
+			query:      "artist=2&artist=3",
+			wantArtist: false,
+		},
+		{
+			name:       "Artist and continent",
+			query:      "artist=1&continent=europe",
+			wantArtist: true,
+			wantConcert: map[string]bool{
+				"london": true,
+				"dallas": false,
+			},
+		},
+		{
+			name:       "Location query",
+			query:      "location=texas",
+			wantArtist: true,
+			wantConcert: map[string]bool{
+				"london": false,
+				"dallas": true,
+			},
+		},
+		{
+			name:       "Year range",
+			query:      "from=2019&to=2019",
+			wantArtist: true,
+			wantConcert: map[string]bool{
+				"london": true,
+				"dallas": false,
+			},
+		},
+		{name: "Invalid artist", query: "artist=queen", wantErr: true},
+		{name: "Invalid continent", query: "continent=atlantis", wantErr: true},
+		{name: "Invalid date", query: "from=yesterday", wantErr: true},
+	}
+
+	concerts := map[string]domain.Concert{
+		"london": concertIn(london, 2019, time.December, 31),
+		"dallas": concertIn(dallas, 2020, time.January, 1),
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				query, _ := url.ParseQuery(tt.query)
+				filter, err := parseCalendarFilter(query)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("parseCalendarFilter() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err != nil {
+					return
+				}
+
+				if got := filter.matchesArtist(queen); got != tt.wantArtist {
+					t.Errorf("matchesArtist() = %v, want %v", got, tt.wantArtist)
+				}
+				for name, want := range tt.wantConcert {
+					if got := filter.matchesConcert(concerts[name]); got != want {
+						t.Errorf("matchesConcert(%s) = %v, want %v", name, got, want)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestArtistCalendarHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		app     *App
+		wantURL string
+	}{
+		{
+			name:    "Public URL",
+			app:     newTestApp(t),
+			wantURL: "URL:" + testBaseURL + "/artists/1-queen",
+		},
+		{
+			name: "Unknown public URL",
+			app:  New(newFakeStore(t), testTemplates, ClockFunc(time.Now), ""),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequest("GET", "/artists/1-queen/concerts.ics", nil)
+				req.Host = "attacker.example"
+				req.SetPathValue("artist", "1-queen")
+				w := httptest.NewRecorder()
+
+				tt.app.ArtistCalendarHandler(w, req)
+
+				if w.Code != http.StatusOK {
+					t.Fatalf("ArtistCalendarHandler() status = %v, want %v", w.Code, http.StatusOK)
+				}
+				body := w.Body.String()
+				if strings.Contains(body, req.Host) {
+					t.Errorf("ArtistCalendarHandler() links to the request's host %s, want the public URL", req.Host)
+				}
+				if got := strings.Count(body, "BEGIN:VEVENT"); got != 2 {
+					t.Errorf("ArtistCalendarHandler() wrote %d events, want 2", got)
+				}
+				if tt.wantURL == "" && strings.Contains(body, "\nURL:") {
+					t.Error("ArtistCalendarHandler() wrote event URLs, want none without a public URL")
+				}
+				if tt.wantURL != "" && !strings.Contains(body, tt.wantURL) {
+					t.Errorf("ArtistCalendarHandler() events are missing %q", tt.wantURL)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"groupie-tracker/chart"
+	"groupie-tracker/domain"
+	"groupie-tracker/stats"
+	"sort"
+	"time"
+)
+
+// maxTimelineRows is the number of rows of the country timeline of the details page
+const maxTimelineRows = 8
+
+// chartFuncs returns the template functions of the chart package, along with the functions converting the
+// page data into chart data:
+//   - bars: stats counts to bars
+//   - travelBars: the most-travelled artists to bars of the number of countries they visited
+//   - albumDelayBins: the careers to a histogram of the years from creation to first album
+//   - careerPoints: the careers to points of the creation year against the first album year
+//   - countryRows: an artist's concerts to a timeline row per country, most concerts first
+//   - artistRows: the compared artists' concerts to a timeline row per artist
+func chartFuncs() map[string]any {
+	funcs := chart.FuncMap()
+	funcs["bars"] = countBars
+	funcs["travelBars"] = travelBars
+	funcs["albumDelayBins"] = albumDelayBins
+	funcs["careerPoints"] = careerPoints
+	funcs["countryRows"] = countryRows
+	funcs["artistRows"] = artistRows
+	return funcs
+}
+
+// countBars returns a bar per count
+func countBars(counts []stats.Count) []chart.Bar {
+	bars := make([]chart.Bar, 0, len(counts))
+	for _, count := range counts {
+		bars = append(bars, chart.Bar{Label: count.Label, Value: float64(count.Value)})
+	}
+	return bars
+}
+
+// travelBars returns a bar per artist, of the number of countries they visited
+func travelBars(travellers []stats.Traveller) []chart.Bar {
+	bars := make([]chart.Bar, 0, len(travellers))
+	for _, traveller := range travellers {
+		bars = append(bars, chart.Bar{Label: traveller.Name, Value: float64(traveller.Countries)})
+	}
+	return bars
+}
+
+// albumDelayBins returns the yearly bins of the years from creation to first album, of the artists whose
+// first album date is known
+func albumDelayBins(careers []stats.Career) []chart.Bin {
+	var delays []float64
+	for _, career := range careers {
+		if career.FirstAlbumYear != 0 {
+			delays = append(delays, float64(career.FirstAlbumYear-career.CreationDate))
+		}
+	}
+	return chart.Bins(delays, 1)
+}
+
+// careerPoints returns a point per artist whose first album date is known, of its creation year against its
+// first album year
+func careerPoints(careers []stats.Career) []chart.Point {
+	var points []chart.Point
+	for _, career := range careers {
+		if career.FirstAlbumYear != 0 {
+			points = append(
+				points,
+				chart.Point{Label: career.Name, X: float64(career.CreationDate), Y: float64(career.FirstAlbumYear)},
+			)
+		}
+	}
+	return points
+}
+
+// countryRows returns a timeline row per country the artist played in, of the concert dates there. The rows are
+// ordered by number of concerts, most first, and limited to maxTimelineRows.
+func countryRows(artist domain.Artist) []chart.TimelineRow {
+	index := make(map[string]int)
+	var rows []chart.TimelineRow
+	for _, concert := range artist.Concerts {
+		i, ok := index[concert.Place.Country]
+		if !ok {
+			i = len(rows)
+			index[concert.Place.Country] = i
+			rows = append(rows, chart.TimelineRow{Label: concert.Place.Country})
+		}
+		rows[i].Dates = append(rows[i].Dates, concert.Date)
+	}
+
+	sort.SliceStable(
+		rows, func(i, j int) bool {
+			return len(rows[i].Dates) > len(rows[j].Dates)
+		},
+	)
+	if len(rows) > maxTimelineRows {
+		rows = rows[:maxTimelineRows]
+	}
+	return rows
+}
+
+// artistRows returns a timeline row per compared artist, of its concert dates
+func artistRows(summaries []domain.ArtistSummary) []chart.TimelineRow {
+	rows := make([]chart.TimelineRow, 0, len(summaries))
+	for _, summary := range summaries {
+		dates := make([]time.Time, 0, len(summary.Artist.Concerts))
+		for _, concert := range summary.Artist.Concerts {
+			dates = append(dates, concert.Date)
+		}
+		rows = append(rows, chart.TimelineRow{Label: summary.Artist.Name, Dates: dates})
+	}
+	return rows
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"groupie-tracker/domain"
+	"groupie-tracker/location"
+	"groupie-tracker/stats"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCountryRows(t *testing.T) {
+	concertIn := func(country string, year int) domain.Concert {
+		return domain.Concert{
+			Place: location.Place{Country: country},
+			Date:  time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC),
+		}
+	}
+
+	tests := []struct {
+		name     string
+		concerts []domain.Concert
+		want     []string
+	}{
+		{
+			name:     "Most concerts first",
+			concerts: []domain.Concert{concertIn("France", 2019), concertIn("Japan", 2019), concertIn("Japan", 2020)},
+			want:     []string{"Japan", "France"},
+		},
+		{
+			name: "Limited rows",
+			concerts: []domain.Concert{
+				concertIn("A", 2019), concertIn("B", 2019), concertIn("C", 2019), concertIn("D", 2019),
+				concertIn("E", 2019), concertIn("F", 2019), concertIn("G", 2019), concertIn("H", 2019),
+				concertIn("I", 2019),
+			},
+			want: []string{"A", "B", "C", "D", "E", "F", "G", "H"},
+		},
+		{
+			name:     "No concerts",
+			concerts: nil,
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var got []string
+				for _, row := range countryRows(domain.Artist{Concerts: tt.concerts}) {
+					got = append(got, row.Label)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("countryRows() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestCareerCharts(t *testing.T) {
+	careers := []stats.Career{
+		{Name: "Queen", CreationDate: 1970, FirstAlbumYear: 1973},
+		{Name: "Unknown album", CreationDate: 1980},
+		{Name: "Coldplay", CreationDate: 1996, FirstAlbumYear: 2000},
+	}
+
+	points := careerPoints(careers)
+	if len(points) != 2 || points[1].X != 1996 || points[1].Y != 2000 {
+		t.Errorf("careerPoints() = %v, want the careers with a known first album", points)
+	}
+
+	bins := albumDelayBins(careers)
+	if len(bins) != 2 || bins[0].Start != 3 || bins[0].Count != 1 || bins[1].Start != 4 || bins[1].Count != 1 {
+		t.Errorf("albumDelayBins() = %v, want a bin for 3 and 4 years", bins)
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"fmt"
+	"groupie-tracker/domain"
+	"groupie-tracker/httperr"
+	"groupie-tracker/xerrors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// minCompare is the minimum number of artists that can be compared
+	minCompare = 2
+	// maxCompare is the maximum number of artists that can be compared
+	maxCompare = 4
+)
+
+// CompareHandler handles HTTP GET requests for the comparison page, at /compare?ids=1,5,12.
+//
+// It renders the creation date, first album, member count, concert count and countries visited of
+// 2 to 4 artists side by side, along with the places where at least two of them played.
+//
+// The handler returns appropriate HTTP status codes:
+//   - 200 OK: Successfully rendered the comparison
+//   - 400 Bad Request: Missing, invalid or duplicate IDs, or too few or too many of them
+//   - 404 Not Found: Non-existent artist ID
+//   - 503 Service Unavailable: The artists could not be fetched
+func (app *App) CompareHandler(w http.ResponseWriter, r *http.Request) {
+	handlerTemplate := "compare.html"
+	comparison, err := app.compareArtists(r.URL.Query())
+	if err != nil {
+		app.renderError(w, r, err)
+		return
+	}
+
+	app.renderPage(w, r, handlerTemplate, comparison)
+}
+
+// CompareAPIHandler handles HTTP GET requests for the JSON comparison of artists, at /api/v1/compare?ids=1,5,12.
+// It accepts the same IDs as CompareHandler, and responds with RFC 9457 problem details on error.
+//
+//	Example response:
+//
+//	```json
+//	{
+//	  "artists": [
+//	    {"id": 1, "name": "Queen", "creationDate": 1970, "firstAlbum": "1973-07-13", "memberCount": 4, ...},
+//	    {"id": 5, "name": "Coldplay", ...}
+//	  ],
+//	  "sharedPlaces": [
+//	    {
+//	      "place": {"slug": "london-uk", "city": "London", ...},
+//	      "dates": {"1": ["2019-03-01"], "5": ["2019-03-01", "2019-03-02"]},
+//	      "sameDates": ["2019-03-01"]
+//	    }
+//	  ]
+//	}
+//	```
+func (app *App) CompareAPIHandler(w http.ResponseWriter, r *http.Request) {
+	comparison, err := app.compareArtists(r.URL.Query())
+	if err != nil {
+		app.errors.Error(w, r, httperr.JSON, err)
+		return
+	}
+
+	writeJSON(w, comparison)
+}
+
+// compareArtists returns the comparison of the artists listed by the `ids` query parameter
+func (app *App) compareArtists(query url.Values) (domain.Comparison, error) {
+	ids, err := parseCompareIDs(query)
+	if err != nil {
+		return domain.Comparison{}, err
+	}
+
+	snapshot, err := app.store.Snapshot()
+	if err != nil {
+		return domain.Comparison{}, err
+	}
+
+	artists := make([]domain.Artist, 0, len(ids))
+	for _, id := range ids {
+		artist, ok := snapshot.Artist(id)
+		if !ok {
+			return domain.Comparison{}, fmt.Errorf("%w: no artist with id %d", xerrors.ErrNotFound, id)
+		}
+		artists = append(artists, artist)
+	}
+	return domain.Compare(artists), nil
+}
+
+// parseCompareIDs returns the artist IDs of the `ids` query parameter, a comma-separated list which may also be
+// repeated, e.g. `ids=1,5&ids=12`. The IDs must be distinct, and there must be between minCompare and maxCompare
+// of them.
+func parseCompareIDs(query url.Values) ([]int, error) {
+	var ids []int
+	seen := make(map[int]bool)
+	for _, value := range query["ids"] {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			id, err := strconv.Atoi(field)
+			if err != nil || id < 1 {
+				return nil, fmt.Errorf("%w: invalid artist id %q", xerrors.ErrBadRequest, field)
+			}
+			if seen[id] {
+				return nil, fmt.Errorf("%w: artist id %d is listed twice", xerrors.ErrBadRequest, id)
+			}
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) < minCompare || len(ids) > maxCompare {
+		return nil, fmt.Errorf(
+			"%w: between %d and %d artist ids are required, got %d",
+			xerrors.ErrBadRequest, minCompare, maxCompare, len(ids),
+		)
+	}
+	return ids, nil
+}
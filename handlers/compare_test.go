@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"groupie-tracker/httperr"
+	"groupie-tracker/xerrors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseCompareIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    []int
+		wantErr bool
+	}{
+		{
+			name:  "Comma-separated",
+			query: "ids=1,5,12",
+			want:  []int{1, 5, 12},
+		},
+		{
+			name:  "Repeated with blanks",
+			query: "ids=12,+5,&ids=1",
+			want:  []int{12, 5, 1},
+		},
+		{
+			name:    "Missing",
+			query:   "",
+			wantErr: true,
+		},
+		{
+			name:    "Single artist",
+			query:   "ids=1",
+			wantErr: true,
+		},
+		{
+			name:    "Too many artists",
+			query:   "ids=1,2,3,4,5",
+			wantErr: true,
+		},
+		{
+			name:    "Not a number",
+			query:   "ids=1,queen",
+			wantErr: true,
+		},
+		{
+			name:    "Not positive",
+			query:   "ids=1,0",
+			wantErr: true,
+		},
+		{
+			name:    "Duplicate",
+			query:   "ids=1,5,1",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				query, err := url.ParseQuery(tt.query)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got, err := parseCompareIDs(query)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("parseCompareIDs() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err != nil && !errors.Is(err, xerrors.ErrBadRequest) {
+					t.Errorf("parseCompareIDs() error = %v, want it to wrap ErrBadRequest", err)
+				}
+				if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("parseCompareIDs() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestCompareAPIHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		expectedCode int
+	}{
+		{
+			name:         "Single artist",
+			method:       "GET",
+			target:       "/api/v1/compare?ids=1",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Invalid ID",
+			method:       "GET",
+			target:       "/api/v1/compare?ids=1,queen",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	app := newTestApp(t)
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(tt.method, tt.target, nil)
+				rr := httptest.NewRecorder()
+
+				app.CompareAPIHandler(rr, req)
+
+				if rr.Code != tt.expectedCode {
+					t.Errorf("CompareAPIHandler() status = %v, want %v", rr.Code, tt.expectedCode)
+				}
+				if got := rr.Header().Get("Content-Type"); got != httperr.ProblemContentType {
+					t.Errorf("CompareAPIHandler() Content-Type = %q, want %q", got, httperr.ProblemContentType)
+				}
+			},
+		)
+	}
+}
@@ -1,75 +1,117 @@
 package handlers
 
 import (
-	"errors"
-	"groupie-tracker/api"
-	"groupie-tracker/xerrors"
-	"log"
+	"fmt"
+	"groupie-tracker/domain"
+	"groupie-tracker/geomap"
 	"net/http"
-	"path/filepath"
-	"text/template"
+	"strconv"
+	"strings"
 )
 
-// DetailsHandler handles HTTP GET requests for artist details.
-//
-// It processes requests with an "id" query parameter, validates the ID,
-// fetches all details for the specified artist, and renders them using
-// the detailsPage.html template.
+// DetailsPageData is the data rendered by the details page template
+type DetailsPageData struct {
+	Artist domain.Artist
+	// Timeline splits the artist's concerts into upcoming and past, relative to Now
+	Timeline domain.Timeline
+	// Map plots the artist's concert locations and tour route
+	Map geomap.Map
+	// Tour holds the metrics of the artist's concerts
+	Tour domain.Tour
+	// Alongside are the artists who played in the same city within DefaultOverlapWindow days of the artist
+	Alongside []domain.ArtistOverlaps
+}
+
+// DetailsHandler handles HTTP GET requests for artist details, at /artists/{artist}.
 //
-// The handler performs the following steps:
-// 1. Validates that the request method is GET
-// 2. Extracts and validates the "id" query parameter
-// 3. Fetches all artist details using api.GetAllDetails
-// 4. Renders the details using the detailsPage.html template
+// The {artist} path segment is the artist's ID and slug, e.g. `1-queen`, see artistPath. The handler looks up
+// the artist with that ID in the cache snapshot, and renders it using the detailsPage.html template. Requests
+// for the artist's ID with another slug, or none, e.g. `/artists/1`, are redirected to the artist's path.
 //
-// If any error occurs during these steps, it renders an appropriate error page
-// with the corresponding HTTP status code.
+// If any error occurs, it renders an appropriate error page with the corresponding HTTP status code.
 //
 // Parameters:
 //   - w http.ResponseWriter: The response writer to send the HTTP response
-//   - r *http.Request: The HTTP request containing the artist ID in query parameters
+//   - r *http.Request: The HTTP request, with the {artist} path value
 //
 // The handler returns appropriate HTTP status codes:
 //   - 200 OK: Successfully rendered artist details
-//   - 405 Method Not Allowed: Request method is not GET
+//   - 301 Moved Permanently: The artist's path differs from the requested path
 //   - 404 Not Found: Invalid or non-existent artist ID
 //   - 500 Internal Server Error: Server-side processing errors
-func DetailsHandler(w http.ResponseWriter, r *http.Request) {
+func (app *App) DetailsHandler(w http.ResponseWriter, r *http.Request) {
 	handlerTemplate := "detailsPage.html"
-	if r.Method != "GET" {
-		RenderErrorPage(w, "Method Not Allowed!", http.StatusMethodNotAllowed)
+	snapshot, err := app.store.Snapshot()
+	if err != nil {
+		app.renderError(w, r, err)
 		return
 	}
 
-	ID := r.URL.Query().Get("id")
-	data, err := api.GetAllDetails(ID)
-	log.Printf("Found err: %v\n", err)
-	if errors.Is(err, xerrors.ErrNotFound) {
-		RenderErrorPage(w, "The artist id entered is out of range!", http.StatusNotFound)
+	artist, ok := artistOf(snapshot, r.PathValue("artist"))
+	if !ok {
+		app.RenderErrorPage(w, r, "The artist id entered is out of range!", http.StatusNotFound)
 		return
-	} else if err != nil {
-		RenderErrorPage(w, "Internal Server Error!", http.StatusInternalServerError)
+	}
+	if path := artistPath(artist); r.URL.Path != path {
+		redirect(w, r, path)
 		return
 	}
 
-	// Define an add function for the Go templates
-	funcMap := template.FuncMap{
-		"add": func(a, b int) int {
-			return a + b
+	alongside, _ := snapshot.Overlaps(artist.ID, DefaultOverlapWindow)
+	app.renderPage(
+		w, r, handlerTemplate, DetailsPageData{
+			Artist:    artist,
+			Timeline:  artist.Timeline(app.clock.Now()),
+			Map:       geomap.New(artist.Concerts),
+			Tour:      artist.Tour(),
+			Alongside: alongside,
 		},
-	}
+	)
+}
 
-	temp, err := template.New(handlerTemplate).Funcs(funcMap).ParseFiles(filepath.Join(templatesDir, handlerTemplate))
+// LegacyDetailsHandler handles HTTP GET requests for the former artist details URL, /details?id={id},
+// redirecting them to the artist's path, see DetailsHandler
+func (app *App) LegacyDetailsHandler(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := app.store.Snapshot()
 	if err != nil {
-		RenderErrorPage(w, "Internal Server Error", http.StatusInternalServerError)
-		log.Printf("Error parsing template: %v\n", err)
+		app.renderError(w, r, err)
 		return
 	}
 
-	err = temp.Execute(w, data)
-	if err != nil {
-		RenderErrorPage(w, "Internal Server error", http.StatusInternalServerError)
-		log.Printf("Error executing template: %v\n", err)
+	ID, err := strconv.Atoi(r.URL.Query().Get("id"))
+	artist, ok := snapshot.Artist(ID)
+	if err != nil || !ok {
+		app.RenderErrorPage(w, r, "The artist id entered is out of range!", http.StatusNotFound)
 		return
 	}
+	redirect(w, r, artistPath(artist))
+}
+
+// artistPath returns the path of the artist's details page: /artists/{id}-{slug}, e.g. `/artists/1-queen`,
+// or /artists/{id} if the artist's name has no slug
+func artistPath(artist domain.Artist) string {
+	if slug := artist.Slug(); slug != "" {
+		return fmt.Sprintf("/artists/%d-%s", artist.ID, slug)
+	}
+	return fmt.Sprintf("/artists/%d", artist.ID)
+}
+
+// artistOf returns the artist of the {artist} path segment, whose ID is before the first hyphen, if any
+func artistOf(snapshot *domain.Snapshot, segment string) (domain.Artist, bool) {
+	id, _, _ := strings.Cut(segment, "-")
+	ID, err := strconv.Atoi(id)
+	if err != nil {
+		return domain.Artist{}, false
+	}
+	return snapshot.Artist(ID)
+}
+
+// redirect permanently redirects the request to the path, keeping the query parameters other than `id`
+func redirect(w http.ResponseWriter, r *http.Request, path string) {
+	query := r.URL.Query()
+	query.Del("id")
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	http.Redirect(w, r, path, http.StatusMovedPermanently)
 }
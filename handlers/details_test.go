@@ -3,17 +3,10 @@ package handlers
 import (
 	"net/http"
 	"net/http/httptest"
-	"os"
-	"path/filepath"
+	"strings"
 	"testing"
 )
 
-func TestMain(m *testing.M) {
-	// During tests, the templates dir is in the parent directory
-	templatesDir = filepath.Join("..", "templates")
-	os.Exit(m.Run())
-}
-
 func TestDetailsHandler(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -25,16 +18,16 @@ func TestDetailsHandler(t *testing.T) {
 		{
 			name:          "Valid GET request with valid ID",
 			method:        "GET",
-			id:            "1",
+			id:            "1-queen",
 			expectedCode:  http.StatusOK,
 			expectedError: false,
 		},
 		{
-			name:          "Invalid method POST",
-			method:        "POST",
+			name:          "ID without slug",
+			method:        "GET",
 			id:            "1",
-			expectedCode:  http.StatusMethodNotAllowed,
-			expectedError: true,
+			expectedCode:  http.StatusMovedPermanently,
+			expectedError: false,
 		},
 		{
 			name:          "Invalid ID",
@@ -52,15 +45,17 @@ func TestDetailsHandler(t *testing.T) {
 		},
 	}
 
+	app := newTestApp(t)
 	for _, tt := range tests {
 		t.Run(
 			tt.name, func(t *testing.T) {
 				// Create a request with the test case parameters
-				req := httptest.NewRequest(tt.method, "/details?id="+tt.id, nil)
+				req := httptest.NewRequest(tt.method, "/artists/"+tt.id, nil)
+				req.SetPathValue("artist", tt.id)
 				w := httptest.NewRecorder()
 
 				// Call the handler
-				DetailsHandler(w, req)
+				app.DetailsHandler(w, req)
 
 				// Check status code
 				if w.Code != tt.expectedCode {
@@ -81,17 +76,75 @@ func TestDetailsHandler(t *testing.T) {
 	}
 }
 
-// TestDetailsHandlerIntegration performs an integration test
-// with the actual API endpoint
-func TestDetailsHandlerIntegration(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
+func TestDetailsHandlerTour(t *testing.T) {
+	app := newTestApp(t)
+	req := httptest.NewRequest("GET", "/artists/1-queen", nil)
+	req.SetPathValue("artist", "1-queen")
+	w := httptest.NewRecorder()
+
+	app.DetailsHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("DetailsHandler returned wrong status code: got %v want %v", w.Code, http.StatusOK)
 	}
 
-	req := httptest.NewRequest("GET", "/details?id=1", nil)
+	// the tour section lists the metrics of Queen's concerts in London and Dallas
+	_, tour, _ := strings.Cut(w.Body.String(), `<dl class="tour">`)
+	tour, _, _ = strings.Cut(tour, "</dl>")
+	for _, want := range []string{"<dd>2</dd>", "32 days", "about 7641 km"} {
+		if !strings.Contains(tour, want) {
+			t.Errorf("Tour section is missing %q:\n%s", want, tour)
+		}
+	}
+}
+
+func TestLegacyDetailsHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		target       string
+		wantStatus   int
+		wantLocation string
+	}{
+		{name: "Artist", target: "/details?id=1", wantStatus: http.StatusMovedPermanently, wantLocation: "/artists/1-queen"},
+		{
+			name: "Other parameters", target: "/details?id=2&view=map", wantStatus: http.StatusMovedPermanently,
+			wantLocation: "/artists/2-pink-floyd?view=map",
+		},
+		{name: "Unknown artist", target: "/details?id=99", wantStatus: http.StatusNotFound},
+		{name: "Invalid ID", target: "/details?id=queen", wantStatus: http.StatusNotFound},
+		{name: "Missing ID", target: "/details", wantStatus: http.StatusNotFound},
+	}
+
+	app := newTestApp(t)
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequest("GET", tt.target, nil)
+				w := httptest.NewRecorder()
+
+				app.LegacyDetailsHandler(w, req)
+
+				if w.Code != tt.wantStatus {
+					t.Errorf("LegacyDetailsHandler() status = %v, want %v", w.Code, tt.wantStatus)
+				}
+				if got := w.Header().Get("Location"); got != tt.wantLocation {
+					t.Errorf("LegacyDetailsHandler() Location = %q, want %q", got, tt.wantLocation)
+				}
+			},
+		)
+	}
+}
+
+// TestDetailsHandlerIntegration performs an integration test of the handler
+// with the page templates, on the artists of newFakeStore
+func TestDetailsHandlerIntegration(t *testing.T) {
+	app := newTestApp(t)
+
+	req := httptest.NewRequest("GET", "/artists/1-queen", nil)
+	req.SetPathValue("artist", "1-queen")
 	w := httptest.NewRecorder()
 
-	DetailsHandler(w, req)
+	app.DetailsHandler(w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status OK; got %v", w.Code)
@@ -104,16 +157,13 @@ func TestDetailsHandlerIntegration(t *testing.T) {
 }
 
 func TestDetailsHandlerNoTemplates(t *testing.T) {
-	originalTemplateDir := templatesDir
-	templatesDir = ""
-	defer func() {
-		templatesDir = originalTemplateDir
-	}()
+	app := newNoTemplatesApp(t)
 
-	req := httptest.NewRequest("GET", "/details?id=1", nil)
+	req := httptest.NewRequest("GET", "/artists/1-queen", nil)
+	req.SetPathValue("artist", "1-queen")
 	w := httptest.NewRecorder()
 
-	DetailsHandler(w, req)
+	app.DetailsHandler(w, req)
 
 	if w.Code != http.StatusInternalServerError {
 		t.Errorf("Expected status OK; got %v", w.Code)
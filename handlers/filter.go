@@ -1,45 +1,145 @@
 package handlers
 
 import (
-	"encoding/json"
-	"groupie-tracker/cache"
-	"log"
+	"groupie-tracker/domain"
+	"groupie-tracker/filter"
+	"groupie-tracker/xtime"
 	"net/http"
-	"path/filepath"
-	"text/template"
+	"slices"
+	"strings"
 )
 
-func Filter(w http.ResponseWriter, r *http.Request) {
+// The bounds of the filter form's creation date and first album date ranges
+const (
+	minCreationDate   = 1958
+	maxCreationDate   = 2015
+	minFirstAlbumYear = 1963
+	maxFirstAlbumYear = 2018
+	maxMembers        = 8
+)
+
+// FilterFormMember is a number of members checkbox of the filter form
+type FilterFormMember struct {
+	Count   int
+	Checked bool
+}
+
+// FilterForm holds the values of the filter form's controls
+type FilterForm struct {
+	Query            string
+	CreationDateFrom int
+	CreationDateTo   int
+	FirstAlbumFrom   int
+	FirstAlbumTo     int
+	Members          []FilterFormMember
+	// Locations are the concert location queries, separated by `; `
+	Locations string
+}
+
+// FilterCard is an artist as displayed by the cards of the filter page, encoded as JSON by the template for the
+// cards of the filter results
+type FilterCard struct {
+	ID    int    `json:"id"`
+	Slug  string `json:"slug"`
+	Image string `json:"image"`
+	Name  string `json:"name"`
+}
+
+// FilterPageData is the data rendered by the filter page template
+type FilterPageData struct {
+	// AllArtists holds the cards of all the artists, encoded as JSON by the template
+	AllArtists []FilterCard
+	Form       FilterForm
+	// Artists are the artists matching the filter request, or all artists if the URL has no filter
+	Artists []domain.Artist
+}
+
+// Filter handles HTTP GET requests for the filter page.
+//
+// The filter form is a regular GET form: the page URL may hold a filter request, as submitted by the form or
+// encoded by filter.EncodeQuery, e.g. `/filter?creation_date.type=range&creation_date.from=1990&creation_date.to=2000`.
+// The matching artists are rendered on the page, so that it works without JavaScript, and filtered views can be
+// linked or bookmarked.
+func (app *App) Filter(w http.ResponseWriter, r *http.Request) {
 	handlerTemplate := "filter.html"
-	if r.Method != "GET" {
-		RenderErrorPage(w, "Method Not Allowed!", http.StatusMethodNotAllowed)
+	snapshot, err := app.store.Snapshot()
+	if err != nil {
+		app.renderError(w, r, err)
 		return
 	}
 
-	// Template data
-	data := struct{ ArtistsJson string }{}
-
-	artists, _, _, _, err := cache.GetCachedData()
+	request, err := filter.DecodeQuery(r.URL.Query())
 	if err != nil {
-		log.Println(err)
-		RenderErrorPage(w, "Internal Server Error", http.StatusInternalServerError)
+		app.RenderErrorPage(w, r, "Bad Request: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	jsonBytes, _ := json.Marshal(artists)
-	data.ArtistsJson = string(jsonBytes)
+	// Template data
+	data := FilterPageData{
+		AllArtists: newFilterCards(snapshot.Artists), Form: newFilterForm(request), Artists: snapshot.Artists,
+	}
 
-	temp, err := template.New(handlerTemplate).ParseFiles(filepath.Join(templatesDir, handlerTemplate))
-	if err != nil {
-		RenderErrorPage(w, "Internal Server Error", http.StatusInternalServerError)
-		log.Printf("Error parsing template: %v\n", err)
-		return
+	if len(r.URL.Query()) > 0 {
+		data.Artists, err = filter.Apply(snapshot.Artists, request)
+		if err != nil {
+			app.RenderErrorPage(w, r, "Bad Request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
 	}
 
-	err = temp.Execute(w, data)
-	if err != nil {
-		RenderErrorPage(w, "Internal Server error", http.StatusInternalServerError)
-		log.Printf("Error executing template: %v\n", err)
-		return
+	app.renderPage(w, r, handlerTemplate, data)
+}
+
+// newFilterCards returns the cards of the artists
+func newFilterCards(artists []domain.Artist) []FilterCard {
+	cards := make([]FilterCard, 0, len(artists))
+	for _, artist := range artists {
+		cards = append(cards, FilterCard{ID: artist.ID, Slug: artist.Slug(), Image: artist.Image, Name: artist.Name})
+	}
+	return cards
+}
+
+// newFilterForm returns the values of the filter form's controls for the filter request. The controls of the
+// filters missing from the request are left at their defaults, which match every artist.
+func newFilterForm(request filter.APIRequestData) FilterForm {
+	form := FilterForm{
+		Query:            request.Query,
+		CreationDateFrom: minCreationDate,
+		CreationDateTo:   maxCreationDate,
+		FirstAlbumFrom:   minFirstAlbumYear,
+		FirstAlbumTo:     maxFirstAlbumYear,
+		Locations:        strings.Join(request.LocationsOfConcertsFilterQuery.In, "; "),
+	}
+
+	if creationDate := request.CreationDateFilterQuery; creationDate.Type == "range" {
+		form.CreationDateFrom = clamp(creationDate.From, minCreationDate, maxCreationDate)
+		form.CreationDateTo = clamp(creationDate.To, form.CreationDateFrom, maxCreationDate)
+	}
+
+	if firstAlbum := request.FirstAlbumDateFilterQuery; firstAlbum.Type == "range" {
+		if from, err := xtime.ParseDate(firstAlbum.From); err == nil {
+			form.FirstAlbumFrom = clamp(from.Year(), minFirstAlbumYear, maxFirstAlbumYear)
+		}
+		if to, err := xtime.ParseDate(firstAlbum.To); err == nil {
+			form.FirstAlbumTo = clamp(to.Year(), form.FirstAlbumFrom, maxFirstAlbumYear)
+		}
+	}
+
+	members := request.NumberOfMembersFilterQuery
+	for count := 1; count <= maxMembers; count++ {
+		checked := members.Type != "in" || slices.Contains(members.In, count)
+		form.Members = append(form.Members, FilterFormMember{Count: count, Checked: checked})
+	}
+	return form
+}
+
+// clamp returns n, limited to the inclusive range [lo, hi]
+func clamp(n, lo, hi int) int {
+	if n < lo {
+		return lo
+	}
+	if n > hi {
+		return hi
 	}
+	return n
 }
@@ -1,8 +1,13 @@
 package handlers
 
 import (
+	"groupie-tracker/filter"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -17,17 +22,10 @@ func TestFilter(t *testing.T) {
 		{
 			name:          "Valid GET request with valid ID",
 			method:        "GET",
-			id:            "1",
+			id:            "1-queen",
 			expectedCode:  http.StatusOK,
 			expectedError: false,
 		},
-		{
-			name:          "Invalid method POST",
-			method:        "POST",
-			id:            "1",
-			expectedCode:  http.StatusMethodNotAllowed,
-			expectedError: true,
-		},
 		{
 			name:          "Invalid ID",
 			method:        "GET",
@@ -44,15 +42,17 @@ func TestFilter(t *testing.T) {
 		},
 	}
 
+	app := newTestApp(t)
 	for _, tt := range tests {
 		t.Run(
 			tt.name, func(t *testing.T) {
 				// Create a request with the test case parameters
-				req := httptest.NewRequest(tt.method, "/details?id="+tt.id, nil)
+				req := httptest.NewRequest(tt.method, "/artists/"+tt.id, nil)
+				req.SetPathValue("artist", tt.id)
 				w := httptest.NewRecorder()
 
 				// Call the handler
-				DetailsHandler(w, req)
+				app.DetailsHandler(w, req)
 
 				// Check status code
 				if w.Code != tt.expectedCode {
@@ -73,17 +73,16 @@ func TestFilter(t *testing.T) {
 	}
 }
 
-// TestDetailsHandlerIntegration performs an integration test
-// with the actual API endpoint
+// TestFilterIntegration performs an integration test of the handler
+// with the page templates, on the artists of newFakeStore
 func TestFilterIntegration(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	app := newTestApp(t)
 
-	req := httptest.NewRequest("GET", "/details?id=1", nil)
+	req := httptest.NewRequest("GET", "/artists/1-queen", nil)
+	req.SetPathValue("artist", "1-queen")
 	w := httptest.NewRecorder()
 
-	DetailsHandler(w, req)
+	app.DetailsHandler(w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status OK; got %v", w.Code)
@@ -96,16 +95,13 @@ func TestFilterIntegration(t *testing.T) {
 }
 
 func TestFilterNoTemplates(t *testing.T) {
-	originalTemplateDir := templatesDir
-	templatesDir = ""
-	defer func() {
-		templatesDir = originalTemplateDir
-	}()
+	app := newNoTemplatesApp(t)
 
-	req := httptest.NewRequest("GET", "/details?id=1", nil)
+	req := httptest.NewRequest("GET", "/artists/1-queen", nil)
+	req.SetPathValue("artist", "1-queen")
 	w := httptest.NewRecorder()
 
-	DetailsHandler(w, req)
+	app.DetailsHandler(w, req)
 
 	if w.Code != http.StatusInternalServerError {
 		t.Errorf("Expected status OK; got %v", w.Code)
@@ -116,3 +112,95 @@ func TestFilterNoTemplates(t *testing.T) {
 		t.Error("Expected non-empty response body")
 	}
 }
+
+func TestFilterPageCards(t *testing.T) {
+	app := newTestApp(t)
+	req := httptest.NewRequest("GET", "/filter", nil)
+	w := httptest.NewRecorder()
+
+	app.Filter(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Filter returned wrong status code: got %v want %v", w.Code, http.StatusOK)
+	}
+	// the page embeds the cards of the artists, rather than the artists with all their concerts
+	_, body, _ := strings.Cut(w.Body.String(), `<script id="data"`)
+	body, _, _ = strings.Cut(body, "</script>")
+	want := `[{"id":1,"slug":"queen","image":"","name":"Queen"},{"id":2,"slug":"pink-floyd","image":"","name":"Pink Floyd"}]`
+	if !strings.Contains(body, want) {
+		t.Errorf("Filter page is missing the artist cards %s", want)
+	}
+	if strings.Contains(body, `"concerts"`) {
+		t.Error("Filter page embeds the concerts of the artists, want only their cards")
+	}
+}
+
+func TestNewFilterForm(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		wantForm    FilterForm
+		wantMembers []int
+	}{
+		{
+			name:  "No filter",
+			query: "",
+			wantForm: FilterForm{
+				CreationDateFrom: minCreationDate, CreationDateTo: maxCreationDate,
+				FirstAlbumFrom: minFirstAlbumYear, FirstAlbumTo: maxFirstAlbumYear,
+			},
+			wantMembers: []int{1, 2, 3, 4, 5, 6, 7, 8},
+		},
+		{
+			name: "Submitted form",
+			query: "query=queen&creation_date.type=range&creation_date.from=1990&creation_date.to=2000" +
+				"&first_album_date.type=range&first_album_date.from=01-01-1970&first_album_date.to=1980" +
+				"&number_of_members.type=in&number_of_members.in=2&number_of_members.in=4" +
+				"&locations_of_concerts.in=Texas%2C+USA%3B+london-uk",
+			wantForm: FilterForm{
+				Query:            "queen",
+				CreationDateFrom: 1990, CreationDateTo: 2000,
+				FirstAlbumFrom: 1970, FirstAlbumTo: 1980,
+				Locations: "Texas, USA; london-uk",
+			},
+			wantMembers: []int{2, 4},
+		},
+		{
+			name:  "Out of bounds range",
+			query: "creation_date.type=range&creation_date.from=1900&creation_date.to=2100",
+			wantForm: FilterForm{
+				CreationDateFrom: minCreationDate, CreationDateTo: maxCreationDate,
+				FirstAlbumFrom: minFirstAlbumYear, FirstAlbumTo: maxFirstAlbumYear,
+			},
+			wantMembers: []int{1, 2, 3, 4, 5, 6, 7, 8},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				values, _ := url.ParseQuery(tt.query)
+				request, err := filter.DecodeQuery(values)
+				if err != nil {
+					t.Fatalf("DecodeQuery() error = %v", err)
+				}
+
+				form := newFilterForm(request)
+				var checked []int
+				for _, member := range form.Members {
+					if member.Checked {
+						checked = append(checked, member.Count)
+					}
+				}
+				if !slices.Equal(checked, tt.wantMembers) {
+					t.Errorf("newFilterForm() checked members = %v, want %v", checked, tt.wantMembers)
+				}
+
+				form.Members = nil
+				if !reflect.DeepEqual(form, tt.wantForm) {
+					t.Errorf("newFilterForm() = %+v, want %+v", form, tt.wantForm)
+				}
+			},
+		)
+	}
+}
@@ -1,3 +1,153 @@
 package handlers
 
-var templatesDir = "templates"
+import (
+	"fmt"
+	"groupie-tracker/assets"
+	"groupie-tracker/domain"
+	"groupie-tracker/httperr"
+	"groupie-tracker/render"
+	"groupie-tracker/stats"
+	"groupie-tracker/xtime"
+	"html/template"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ArtistStore is the source of the artists data, e.g. a cache.Cache
+type ArtistStore interface {
+	// Snapshot returns the typed artists data
+	Snapshot() (*domain.Snapshot, error)
+	// Stats returns the aggregate statistics of the artists
+	Stats() (stats.Stats, error)
+	// Version returns the version of the data, which changes when the data does
+	Version() (string, error)
+}
+
+// Renderer renders the page templates, e.g. a render.Registry, see NewTemplates. Execute renders the error page.
+type Renderer interface {
+	httperr.Renderer
+	// ExecuteWith renders the named template with the data into w, with the given functions replacing the
+	// functions of the same name. The key identifies the functions, see render.Registry.ExecuteWith.
+	ExecuteWith(w io.Writer, name, key string, funcs template.FuncMap, data any) error
+}
+
+// Clock tells the current time. The details page splits concerts into upcoming and past relative to it.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to a Clock, e.g. ClockFunc(time.Now), or a function returning a past date,
+// to browse the data as of that date
+type ClockFunc func() time.Time
+
+// Now returns f()
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// App holds the dependencies of the handlers, which are its methods
+type App struct {
+	store     ArtistStore
+	templates Renderer
+	// errors writes the error responses, rendering the error page with the templates
+	errors *httperr.Writer
+	clock  Clock
+	// baseURL is the public URL of the site, without a trailing slash, e.g. `https://groupie.example.com`.
+	// It is empty if unknown.
+	baseURL string
+}
+
+// New returns an app serving the artists of the store, rendering the pages with the templates, as of the
+// clock's time. The baseURL is the public URL of the site, e.g. `https://groupie.example.com`, which the
+// calendar feeds link the artist pages with. The links are left out if it is empty: the Host header of the
+// requests is not trusted for them, since the feeds are cached and subscribed to.
+func New(store ArtistStore, templates Renderer, clock Clock, baseURL string) *App {
+	return &App{
+		store: store, templates: templates, errors: httperr.NewWriter(templates), clock: clock,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Store returns the source of the app's artists data
+func (app *App) Store() ArtistStore {
+	return app.store
+}
+
+// Errors returns the writer of the app's error responses, which renders the error page with the app's templates
+func (app *App) Errors() *httperr.Writer {
+	return app.errors
+}
+
+// DataVersion returns the version of the responses built from the app's data: the version of the data, and the
+// date the details page splits concerts into upcoming and past at. It is suitable for middleware.ETag.
+func (app *App) DataVersion() (string, error) {
+	version, err := app.store.Version()
+	return version + "-" + app.clock.Now().Format("20060102"), err
+}
+
+// NewTemplates returns the page templates at the root of fsys, parsed all at once, including the error page
+// template, see httperr.Writer. The templates refer to the static files served by static, which may be nil to
+// refer to their plain paths under /static/. In dev mode, the templates are parsed again whenever their files
+// change, so that they can be edited without restarting the server.
+func NewTemplates(fsys fs.FS, static *assets.Server, dev bool) (*render.Registry, error) {
+	templates := render.New(fsys, templateFuncs(static), dev)
+	return templates, templates.Load()
+}
+
+// templateFuncs returns the functions shared by all page templates: the chart functions (see chartFuncs),
+// and the following
+//   - add: the sum of two integers
+//   - artistPath: the path of an artist's details page, e.g. `/artists/1-queen`
+//   - asset: the content-hashed URL path of a static file served by static, e.g. `css/index.css`
+//   - formatDate: a date in the default locale, or `unknown` for the zero time. Replaced by formatDateFuncs
+//     to format dates in the client's preferred locale.
+//   - formatKm: a distance in kilometres, rounded to the kilometre
+func templateFuncs(static *assets.Server) template.FuncMap {
+	funcs := template.FuncMap(chartFuncs())
+	funcs["add"] = func(a, b int) int {
+		return a + b
+	}
+	funcs["artistPath"] = artistPath
+	funcs["asset"] = func(name string) string {
+		if static == nil {
+			return "/static/" + name
+		}
+		return static.Path(name)
+	}
+	funcs["formatKm"] = func(km float64) string {
+		return fmt.Sprintf("%.0f km", km)
+	}
+	for name, fn := range formatDateFuncs(xtime.Locale("")) {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+// formatDateFuncs returns the formatDate template function, formatting dates in the given locale
+func formatDateFuncs(locale string) template.FuncMap {
+	return template.FuncMap{
+		"formatDate": func(date time.Time) string {
+			if date.IsZero() {
+				return "unknown"
+			}
+			return xtime.Format(date, locale)
+		},
+	}
+}
+
+// renderPage renders the named page template with the data, with dates formatted in the client's preferred
+// locale. Responds with the error page if the template can't be rendered.
+func (app *App) renderPage(w http.ResponseWriter, r *http.Request, name string, data any) {
+	locale := xtime.Locale(r.Header.Get("Accept-Language"))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Add("Vary", "Accept-Language")
+	err := app.templates.ExecuteWith(w, name, locale, formatDateFuncs(locale), data)
+	if err != nil {
+		app.RenderErrorPage(w, r, "Internal Server Error", http.StatusInternalServerError)
+		log.Printf("Error rendering template %s: %v\n", name, err)
+	}
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"groupie-tracker/api"
+	"groupie-tracker/domain"
+	"groupie-tracker/render"
+	"groupie-tracker/stats"
+	"groupie-tracker/xerrors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// testTemplates holds the page templates of the templates directory
+var testTemplates *render.Registry
+
+func TestMain(m *testing.M) {
+	// During tests, the templates dir is in the parent directory
+	var err error
+	testTemplates, err = NewTemplates(os.DirFS(filepath.Join("..", "templates")), nil, false)
+	if err != nil {
+		log.Fatalf("Error loading templates: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+// testDate is the date the test apps split concerts into upcoming and past at
+var testDate = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// fakeStore serves the artists of a snapshot, or fails if err is set
+type fakeStore struct {
+	snapshot *domain.Snapshot
+	err      error
+}
+
+func (s fakeStore) Snapshot() (*domain.Snapshot, error) {
+	return s.snapshot, s.err
+}
+
+func (s fakeStore) Stats() (stats.Stats, error) {
+	if s.err != nil {
+		return stats.Stats{}, s.err
+	}
+	return stats.New(s.snapshot), nil
+}
+
+func (s fakeStore) Version() (string, error) {
+	return "test", s.err
+}
+
+// newFakeStore returns a store of two artists: Queen, with ID 1, and Pink Floyd, with ID 2, who played in London
+// 2 days apart
+func newFakeStore(t *testing.T) fakeStore {
+	t.Helper()
+	snapshot, err := domain.NewSnapshot(
+		[]api.Artist{
+			{
+				ID: 1, Name: "Queen", Members: []string{"Freddie Mercury", "Brian May"},
+				CreationDate: 1970, FirstAlbum: "14-12-1973",
+			},
+			{ID: 2, Name: "Pink Floyd", Members: []string{"Roger Waters"}, CreationDate: 1965, FirstAlbum: "05-08-1967"},
+		},
+		[]api.Location{
+			{Id: 1, Locations: []string{"london-uk", "dallas-usa"}},
+			{Id: 2, Locations: []string{"london-uk"}},
+		},
+		[]api.Relations{
+			{Id: 1, DatesLocation: map[string][]string{"london-uk": {"31-12-2019"}, "dallas-usa": {"01-02-2020"}}},
+			{Id: 2, DatesLocation: map[string][]string{"london-uk": {"05-07-1969", "29-12-2019"}}},
+		},
+		testDate,
+	)
+	if err != nil {
+		t.Fatalf("NewSnapshot() error = %v", err)
+	}
+	return fakeStore{snapshot: snapshot}
+}
+
+// testBaseURL is the public URL of the site of the test apps
+const testBaseURL = "https://groupie.example.com"
+
+// newTestApp returns an app serving the artists of newFakeStore with the page templates, as of testDate
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+	return New(newFakeStore(t), testTemplates, ClockFunc(func() time.Time { return testDate }), testBaseURL)
+}
+
+// newNoTemplatesApp returns an app serving the artists of newFakeStore without any page template
+func newNoTemplatesApp(t *testing.T) *App {
+	t.Helper()
+	templates := render.New(fstest.MapFS{}, templateFuncs(nil), false)
+	return New(newFakeStore(t), templates, ClockFunc(func() time.Time { return testDate }), testBaseURL)
+}
+
+func TestAppUnavailable(t *testing.T) {
+	app := New(fakeStore{err: xerrors.ErrUnavailable}, testTemplates, ClockFunc(time.Now), "")
+
+	tests := []struct {
+		name    string
+		target  string
+		handler http.HandlerFunc
+	}{
+		{name: "Index", target: "/", handler: app.IndexHandler},
+		{name: "Stats", target: "/stats", handler: app.StatsHandler},
+		{name: "Stats API", target: "/api/v1/stats", handler: app.StatsAPIHandler},
+		{name: "Search", target: "/search-suggestions?q=queen", handler: app.SearchHandler},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequest("GET", tt.target, nil)
+				w := httptest.NewRecorder()
+
+				tt.handler(w, req)
+
+				if w.Code != http.StatusServiceUnavailable {
+					t.Errorf("status = %v, want %v", w.Code, http.StatusServiceUnavailable)
+				}
+			},
+		)
+	}
+}
+
+func TestAppDataVersion(t *testing.T) {
+	app := newTestApp(t)
+	if got, err := app.DataVersion(); err != nil || got != "test-20200101" {
+		t.Errorf("DataVersion() = %q, %v, want %q", got, err, "test-20200101")
+	}
+
+	failing := New(fakeStore{err: errors.New("offline")}, testTemplates, ClockFunc(time.Now), "")
+	if _, err := failing.DataVersion(); err == nil {
+		t.Error("DataVersion() error = nil, want the store's error")
+	}
+}
@@ -1,31 +1,25 @@
 package handlers
 
 import (
-	"encoding/json"
-	"fmt"
-	"groupie-tracker/api"
-	"groupie-tracker/cache"
-	"html/template"
+	"groupie-tracker/domain"
+	"groupie-tracker/location"
 	"net/http"
-	"path/filepath"
 	"strconv"
 	"strings"
 )
 
 type TemplateData struct {
-	Artists   []api.Artist
+	Artists   []domain.Artist
 	Query     string
 	NoResults bool
 }
 
-// IndexHandler handles HTTP GET requests for the main index page.
+// IndexHandler handles HTTP GET requests for the main index page, at /.
 //
 // It serves as the main entry point of the application, displaying a list of all artists.
 // The handler performs the following steps:
-// 1. Validates that the request method is GET
-// 2. Ensures the request path is exactly "/"
-// 3. Fetches the list of all artists using api.GetArtists
-// 4. Renders the artists data using the index.html template
+// 1. Fetches the list of all artists from the cache snapshot
+// 2. Renders the artists data using the index.html template
 //
 // If any error occurs during these steps, it renders an appropriate error page
 // with the corresponding HTTP status code.
@@ -36,41 +30,16 @@ type TemplateData struct {
 //
 // The handler returns appropriate HTTP status codes:
 //   - 200 OK: Successfully rendered the index page
-//   - 405 Method Not Allowed: Request method is not GET
-//   - 404 Not Found: URL path is not "/"
 //   - 500 Internal Server Error: Server-side processing errors
-func IndexHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" {
-		RenderErrorPage(w, "Method Not Allowed!", http.StatusMethodNotAllowed)
-		return
-	}
-
-	if r.URL.Path != "/" {
-		RenderErrorPage(w, "Page Not Found!", http.StatusNotFound)
-		return
-	}
-
+func (app *App) IndexHandler(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("query") // Get the query parameter
-	artists, locations, _, _, err := cache.GetCachedData()
+	snapshot, err := app.store.Snapshot()
 	if err != nil {
-		RenderErrorPage(w, "Internal Server Error: "+err.Error(), http.StatusInternalServerError)
+		app.renderError(w, r, err)
 		return
 	}
-	for i := range artists {
-		// Ensure we don't exceed the length of the locations slice
-		if i < len(locations) {
-			// Convert locations[i].Locations (a []string) into a JSON string
-			locationData, err := json.Marshal(locations[i].Locations)
-			if err != nil {
-				fmt.Printf("Error marshalling locations for artist %d: %v\n", artists[i].ID, err)
-				continue
-			}
-			// Assign the serialized JSON string to the Locations field of the artist
-			artists[i].Locations = string(locationData)
-		}
-	}
 
-	filteredArtists := filterArtists(artists, query)
+	filteredArtists := filterArtists(snapshot.Artists, query)
 
 	data := TemplateData{
 		Artists:   filteredArtists,
@@ -78,28 +47,19 @@ func IndexHandler(w http.ResponseWriter, r *http.Request) {
 		NoResults: len(filteredArtists) == 0 && query != "",
 	}
 
-	temp, err := template.ParseFiles(filepath.Join(templatesDir, "index.html"))
-	if err != nil {
-		RenderErrorPage(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
-
-	err = temp.Execute(w, data)
-
-	if err != nil {
-		RenderErrorPage(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
+	app.renderPage(w, r, "index.html", data)
 }
 
-// filterArtists filters the list of artists based on the search query
-func filterArtists(artists []api.Artist, query string) []api.Artist {
+// filterArtists filters the list of artists based on the search query.
+// The query matches the artists' concert locations component by component.
+func filterArtists(artists []domain.Artist, query string) []domain.Artist {
 	if query == "" {
 		return artists
 	}
 
+	locationQuery := location.ParseQuery(query)
 	query = strings.ToLower(query)
-	var result []api.Artist
+	var result []domain.Artist
 
 	for _, a := range artists {
 		// Artist/band name matches
@@ -117,7 +77,7 @@ func filterArtists(artists []api.Artist, query string) []api.Artist {
 		}
 
 		// First album dates
-		if strings.Contains(strings.ToLower(a.FirstAlbum), query) {
+		if strings.Contains(a.FirstAlbum.Format("02-01-2006"), query) {
 			result = append(result, a)
 			continue
 		}
@@ -129,9 +89,11 @@ func filterArtists(artists []api.Artist, query string) []api.Artist {
 		}
 
 		// locations
-		if strings.Contains(strings.ToLower(a.Locations), strings.ToLower(query)) {
-			result = append(result, a)
-			continue
+		for _, place := range a.Locations {
+			if locationQuery.Matches(place) {
+				result = append(result, a)
+				break
+			}
 		}
 	}
 	return result
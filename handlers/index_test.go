@@ -22,29 +22,9 @@ func TestIndexHandler(t *testing.T) {
 			expectedCode:  http.StatusOK,
 			expectedError: false,
 		},
-		{
-			name:          "Invalid method POST",
-			method:        "POST",
-			path:          "/",
-			expectedCode:  http.StatusMethodNotAllowed,
-			expectedError: true,
-		},
-		{
-			name:          "Invalid path",
-			method:        "GET",
-			path:          "/invalid",
-			expectedCode:  http.StatusNotFound,
-			expectedError: true,
-		},
-		{
-			name:          "Root path with trailing slash",
-			method:        "GET",
-			path:          "//",
-			expectedCode:  http.StatusNotFound,
-			expectedError: true,
-		},
 	}
 
+	app := newTestApp(t)
 	for _, tt := range tests {
 		t.Run(
 			tt.name, func(t *testing.T) {
@@ -53,7 +33,7 @@ func TestIndexHandler(t *testing.T) {
 				w := httptest.NewRecorder()
 
 				// Call the handler
-				IndexHandler(w, req)
+				app.IndexHandler(w, req)
 
 				// Check status code
 				if w.Code != tt.expectedCode {
@@ -74,17 +54,15 @@ func TestIndexHandler(t *testing.T) {
 	}
 }
 
-// TestIndexHandlerIntegration performs an integration test
-// with the actual API endpoint
+// TestIndexHandlerIntegration performs an integration test of the handler
+// with the page templates, on the artists of newFakeStore
 func TestIndexHandlerIntegration(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	app := newTestApp(t)
 
 	req := httptest.NewRequest("GET", "/", nil)
 	w := httptest.NewRecorder()
 
-	IndexHandler(w, req)
+	app.IndexHandler(w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status OK; got %v", w.Code)
@@ -106,16 +84,12 @@ func TestIndexHandlerIntegration(t *testing.T) {
 }
 
 func TestIndexHandlerNoTemplates(t *testing.T) {
-	originalTemplateDir := templatesDir
-	templatesDir = ""
-	defer func() {
-		templatesDir = originalTemplateDir
-	}()
+	app := newNoTemplatesApp(t)
 
 	req := httptest.NewRequest("GET", "/", nil)
 	w := httptest.NewRecorder()
 
-	IndexHandler(w, req)
+	app.IndexHandler(w, req)
 
 	if w.Code != http.StatusInternalServerError {
 		t.Errorf("Expected status %v; got %v", http.StatusInternalServerError, w.Code)
@@ -1,47 +1,30 @@
 package handlers
 
 import (
-	"groupie-tracker/api"
-	"html/template"
-	"log"
+	"groupie-tracker/httperr"
 	"net/http"
-	"path/filepath"
-	"strconv"
 )
 
-// RenderErrorPage renders an HTML error page with the provided error message and HTTP status code.
+// RenderErrorPage responds with the provided error message and HTTP status code.
 //
 // Parameters:
-//   - w http.ResponseWriter: The response writer to send the rendered HTML page
+//   - w http.ResponseWriter: The response writer to send the error response
+//   - r *http.Request: The request that failed, whose Accept header selects the response format
 //   - errorText string: The error message to display on the page
 //   - statusCode int: The HTTP status code to set in the response
 //
-// The function sets the HTTP status code, then renders the error page template (errorPage.html)
-// with the provided error message and status code. If there are any errors during template
-// parsing or execution, it returns a generic 500 Internal Server Error response.
+// The response is written by the app's httperr.Writer: the error page template (errorPage.html) by default, or an
+// RFC 9457 problem details JSON object, or plain text, if the client prefers them.
 //
 // Example usage:
 //
-//	RenderErrorPage(w, "Resource not found", http.StatusNotFound)
-func RenderErrorPage(w http.ResponseWriter, errorText string, statusCode int) {
-	w.WriteHeader(statusCode)
-
-	content := api.ErrorContent{
-		Message: errorText,
-		Code:    strconv.Itoa(statusCode),
-	}
-
-	temp, err := template.ParseFiles(filepath.Join(templatesDir, "errorPage.html"))
-	if err != nil {
-		http.Error(w, "Internal Server Error!", http.StatusInternalServerError)
-		log.Printf("Error parsing templates: %v", err)
-		return
-	}
+//	app.RenderErrorPage(w, r, "Resource not found", http.StatusNotFound)
+func (app *App) RenderErrorPage(w http.ResponseWriter, r *http.Request, errorText string, statusCode int) {
+	app.errors.Write(w, r, httperr.HTML, httperr.New(statusCode, errorText))
+}
 
-	err = temp.Execute(w, content)
-	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		log.Printf("Error executing template: %v", err)
-		return
-	}
+// renderError responds with the error, with the status code mapped from the xerrors sentinel it wraps,
+// e.g. 503 Service Unavailable for ArtistStore.Snapshot errors
+func (app *App) renderError(w http.ResponseWriter, r *http.Request, err error) {
+	app.errors.Error(w, r, httperr.HTML, err)
 }
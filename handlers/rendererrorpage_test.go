@@ -3,6 +3,8 @@ package handlers
 import (
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -12,9 +14,9 @@ func Test_renderErrorPage(t *testing.T) {
 		statusCode int
 	}
 
-	mockHandler := func(arg args) func(w http.ResponseWriter, r *http.Request) {
+	mockHandler := func(app *App, arg args) func(w http.ResponseWriter, r *http.Request) {
 		return func(w http.ResponseWriter, r *http.Request) {
-			RenderErrorPage(w, arg.errorText, arg.statusCode)
+			app.RenderErrorPage(w, r, arg.errorText, arg.statusCode)
 		}
 	}
 
@@ -62,24 +64,36 @@ func Test_renderErrorPage(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(
 			tt.name, func(t *testing.T) {
-				var originalTemplateDir = templatesDir
+				app := newTestApp(t)
 				if tt.noTemplate {
-					templatesDir = ""
-					defer func() {
-						templatesDir = originalTemplateDir
-					}()
+					app = newNoTemplatesApp(t)
 				}
 
 				req := httptest.NewRequest("GET", "/", nil)
 				w := httptest.NewRecorder()
 
 				// Call the handler
-				handler := mockHandler(tt.args)
+				handler := mockHandler(app, tt.args)
 				handler(w, req)
 
 				if w.Code != tt.args.statusCode {
 					t.Errorf("got %d, want %d", w.Code, tt.args.statusCode)
 				}
+				if !strings.Contains(w.Body.String(), tt.args.errorText) {
+					t.Errorf("body = %q, want it to contain %q", w.Body.String(), tt.args.errorText)
+				}
+
+				// without the error page template, the error is written as plain text
+				wantContentType := "text/html; charset=utf-8"
+				if tt.noTemplate {
+					wantContentType = "text/plain; charset=utf-8"
+				}
+				if got := w.Header().Get("Content-Type"); got != wantContentType {
+					t.Errorf("Content-Type = %q, want %q", got, wantContentType)
+				}
+				if !tt.noTemplate && !strings.Contains(w.Body.String(), strconv.Itoa(tt.args.statusCode)) {
+					t.Errorf("error page is missing the status code %d", tt.args.statusCode)
+				}
 			},
 		)
 	}
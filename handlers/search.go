@@ -2,66 +2,115 @@ package handlers
 
 import (
 	"encoding/json"
-	"groupie-tracker/cache"
+	"groupie-tracker/domain"
+	"groupie-tracker/httperr"
+	"groupie-tracker/location"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
+// SearchResponseVersion is the version of the structured search suggestions payload
+// returned by SearchHandler, unless the legacy payload is requested with `v=1`
+const SearchResponseVersion = 2
+
+// SuggestionKind describes which attribute of an artist a search suggestion was matched against
+type SuggestionKind string
+
+const (
+	KindArtist       SuggestionKind = "artist"
+	KindMember       SuggestionKind = "member"
+	KindLocation     SuggestionKind = "location"
+	KindFirstAlbum   SuggestionKind = "first_album"
+	KindCreationDate SuggestionKind = "creation_date"
+)
+
+// MatchSpan holds the character offsets of the query match within a suggestion's value,
+// as the half-open range [Start, End)
+type MatchSpan struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Suggestion is a single structured search suggestion
+type Suggestion struct {
+	Kind       SuggestionKind `json:"kind"`
+	ArtistID   int            `json:"artist_id"`
+	ArtistName string         `json:"artist_name"`
+	Value      string         `json:"value"`
+	Match      MatchSpan      `json:"match"`
+	Score      int            `json:"score"`
+}
+
+// SearchResponse is the versioned search suggestions payload
+type SearchResponse struct {
+	Version     int          `json:"version"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// SearchHandlerResponse is the legacy (version 1) search suggestion payload,
+// where From is a human-readable description of the suggestion's origin
 type SearchHandlerResponse struct {
 	Suggestion string `json:"suggestion"`
 	From       string `json:"from"`
 }
 
+// legacy converts the suggestion to the version 1 payload
+func (s Suggestion) legacy() SearchHandlerResponse {
+	from := "location"
+	switch s.Kind {
+	case KindArtist:
+		from = "artist/band"
+	case KindMember:
+		from = "member (" + s.ArtistName + ")"
+	case KindFirstAlbum:
+		from = "first album date (" + s.ArtistName + ")"
+	case KindCreationDate:
+		from = "creation date (" + s.ArtistName + ")"
+	}
+
+	return SearchHandlerResponse{Suggestion: s.Value, From: from}
+}
+
 // SearchHandler exposes a GET request API that accepts a query for a search for an artist,
 // album, or concert location.
 //
-//				Example usage:
-//
-//				Request query: `/?q=queen`
+//	Example usage:
 //
-//				Response:
+//	Request query: `/?q=queen`
 //
-//				```json
-//				[
-//		 			{
-//		 			  "suggestion": "Queen",
-//		 			  "from": "artist/band"
-//		 			},
-//		 			{
-//		 			  "suggestion": "queensland-australia",
-//		 			  "from": "location"
-//		 			}
-//				]
-//				```
+//	Response:
 //
-//				Making a request with the `init` query, allows for initialization functions to make blank queries, that
-//				may be helpful when initializing search suggestions.
+//	```json
+//	{
+//	  "version": 2,
+//	  "suggestions": [
+//	    {
+//	      "kind": "artist",
+//	      "artist_id": 1,
+//	      "artist_name": "Queen",
+//	      "value": "Queen",
+//	      "match": {"start": 0, "end": 5},
+//	      "score": 113
+//	    }
+//	  ]
+//	}
+//	```
 //
-//			 Below is an example of how to initialize all search suggestions:
+//	Suggestions are ordered by score, highest first. The score ranks suggestions that match the whole
+//	query (+100), start with the query (+10), contain the query (+2) and end with the query (+1).
 //
-//				Request query: `/?init=true`
+//	Concert locations are suggested if they match the query as a location query, component by component,
+//	as in the filters, see location.Query: `new york` suggests `new_york-usa`, but `york` doesn't.
 //
-//				Response:
+//	Making a request with the `init` query, allows for initialization functions to make blank queries, that
+//	may be helpful when initializing search suggestions, e.g. `/?init=true`.
 //
-//				```json
-//				[
-//	    			{
-//	    			  "suggestion": "Queen",
-//	    			  "from": "artist/band"
-//	    			},
-//	    			{
-//	    			  "suggestion": "queensland-australia",
-//	    			  "from": "location"
-//	    			}
-//	  		]
-//	  		```
-func SearchHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-
+//	Clients that still expect the legacy payload, a list of `{"suggestion", "from"}` objects,
+//	where `from` is a human string such as `member (Queen)`, may request it with `v=1`, e.g. `/?q=queen&v=1`.
+func (app *App) SearchHandler(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	// whether this api request should return all suggestions if the query, q, is blank
 	initSuggestions := false
@@ -69,77 +118,145 @@ func SearchHandler(w http.ResponseWriter, r *http.Request) {
 		initQuery := r.URL.Query().Get("init")
 		initSuggestions = initQuery == "true"
 	}
+	legacy := r.URL.Query().Get("v") == "1"
 
+	var suggestions []Suggestion
 	// ignore empty search queries, return an empty suggestion list
-	if strings.TrimSpace(query) == "" && !initSuggestions {
-		_ = json.NewEncoder(w).Encode([]SearchHandlerResponse{})
-		return
+	if strings.TrimSpace(query) != "" || initSuggestions {
+		snapshot, err := app.store.Snapshot()
+		if err != nil {
+			app.errors.Error(w, r, httperr.JSON, err)
+			return
+		}
+		suggestions = findSuggestions(snapshot.Artists, query)
 	}
 
-	var suggestions []SearchHandlerResponse
-	artists, locations, _, _, err := cache.GetCachedData()
-	if err != nil {
-		_ = json.NewEncoder(w).Encode(suggestions)
+	w.Header().Set("Content-Type", "application/json")
+
+	if legacy {
+		response := make([]SearchHandlerResponse, 0, len(suggestions))
+		for _, suggestion := range suggestions {
+			response = append(response, suggestion.legacy())
+		}
+		_ = json.NewEncoder(w).Encode(response)
 		return
 	}
 
-	for _, artist := range artists {
-		// Artist/band name
-		if strings.Contains(strings.ToLower(artist.Name), strings.ToLower(query)) {
-			suggestions = append(
-				suggestions, SearchHandlerResponse{
-					Suggestion: artist.Name,
-					From:       "artist/band",
-				},
-			)
+	sort.SliceStable(
+		suggestions, func(i, j int) bool {
+			return suggestions[i].Score > suggestions[j].Score
+		},
+	)
+	if suggestions == nil {
+		suggestions = []Suggestion{}
+	}
+	_ = json.NewEncoder(w).Encode(SearchResponse{Version: SearchResponseVersion, Suggestions: suggestions})
+}
+
+// findSuggestions returns the suggestions for every artist attribute that contains the query, and every concert
+// location that matches it, see matchLocation. An empty query matches everything
+func findSuggestions(artists []domain.Artist, query string) (suggestions []Suggestion) {
+	suggest := func(kind SuggestionKind, artist domain.Artist, value string, span MatchSpan, score int) {
+		suggestions = append(
+			suggestions, Suggestion{
+				Kind:       kind,
+				ArtistID:   artist.ID,
+				ArtistName: artist.Name,
+				Value:      value,
+				Match:      span,
+				Score:      score,
+			},
+		)
+	}
+	add := func(kind SuggestionKind, artist domain.Artist, value string) {
+		if span, score, ok := matchSuggestion(value, query); ok {
+			suggest(kind, artist, value, span, score)
 		}
+	}
 
-		// Members
+	for _, artist := range artists {
+		add(KindArtist, artist, artist.Name)
 		for _, member := range artist.Members {
-			if strings.Contains(strings.ToLower(member), strings.ToLower(query)) {
-				suggestions = append(
-					suggestions, SearchHandlerResponse{
-						Suggestion: member,
-						From:       "member (" + artist.Name + ")",
-					},
-				)
-			}
+			add(KindMember, artist, member)
 		}
-
-		// First album date
-		if strings.Contains(strings.ToLower(artist.FirstAlbum), strings.ToLower(query)) {
-			suggestions = append(
-				suggestions, SearchHandlerResponse{
-					Suggestion: artist.FirstAlbum,
-					From:       "first album date (" + artist.Name + ")",
-				},
-			)
+		if !artist.FirstAlbum.IsZero() {
+			add(KindFirstAlbum, artist, artist.FirstAlbum.Format("02-01-2006"))
 		}
+		add(KindCreationDate, artist, strconv.Itoa(artist.CreationDate))
+	}
 
-		// Creation date
-		if strings.Contains(strconv.Itoa(artist.CreationDate), query) {
-			suggestions = append(
-				suggestions, SearchHandlerResponse{
-					Suggestion: strconv.Itoa(artist.CreationDate),
-					From:       "creation date (" + artist.Name + ")",
-				},
-			)
+	// list the location suggestions after those of every other kind
+	locationQuery := location.ParseQuery(query)
+	for _, artist := range artists {
+		for _, place := range artist.Locations {
+			if locationQuery.IsZero() {
+				add(KindLocation, artist, place.Slug)
+			} else if span, score, ok := matchLocation(place, locationQuery); ok {
+				suggest(KindLocation, artist, place.Slug, span, score)
+			}
 		}
+	}
+
+	return
+}
 
+// matchLocation reports whether the place matches the location query, see location.Query.Matches. If it does,
+// the character offsets of the match within the place's slug and the suggestion's ranking score are returned,
+// as by matchSuggestion for the single component of the query, e.g. `new york` within `new_york-usa`. The
+// queries of several components, or naming a country by an alias, such as `London, UK`, match the whole slug.
+func matchLocation(place location.Place, query location.Query) (span MatchSpan, score int, ok bool) {
+	if !query.Matches(place) {
+		return MatchSpan{}, 0, false
 	}
 
-	for _, location := range locations {
-		for _, loc := range location.Locations {
-			if strings.Contains(strings.ToLower(loc), strings.ToLower(query)) {
-				suggestions = append(
-					suggestions, SearchHandlerResponse{
-						Suggestion: loc,
-						From:       "location",
-					},
-				)
-			}
+	// the components of the slug are separated by hyphens, and their words by underscores
+	spaced := strings.NewReplacer("_", " ", "-", " ").Replace(place.Slug)
+	if len(query.Terms) == 1 && query.City == "" && query.Region == "" && query.Country == "" {
+		if span, score, ok := matchSuggestion(spaced, query.Terms[0]); ok {
+			return span, score, true
 		}
 	}
+	return MatchSpan{Start: 0, End: utf8.RuneCountInString(place.Slug)}, 2, true
+}
+
+// matchSuggestion reports whether value contains the query, ignoring case. If it does, the character offsets
+// of the first match within value and the suggestion's ranking score are returned. The characters are compared
+// with simple case folding, one by one, so that the offsets hold for value itself, unlike those within its lower
+// case, which may have more characters, e.g. `İ` lowers to `i̇`.
+func matchSuggestion(value, query string) (span MatchSpan, score int, ok bool) {
+	runes, queryRunes := []rune(value), []rune(query)
+
+	start := indexFold(runes, queryRunes)
+	if start < 0 {
+		return MatchSpan{}, 0, false
+	}
+
+	span.Start = start
+	span.End = start + len(queryRunes)
 
-	_ = json.NewEncoder(w).Encode(suggestions)
+	if len(queryRunes) == 0 {
+		return span, 0, true
+	}
+	if len(runes) == len(queryRunes) {
+		score += 100
+	}
+	if start == 0 {
+		score += 10
+	}
+	score += 2
+	if strings.EqualFold(string(runes[len(runes)-len(queryRunes):]), query) {
+		score += 1
+	}
+
+	return span, score, true
+}
+
+// indexFold returns the offset of the first occurrence of query in value, ignoring case, or -1 if there is none
+func indexFold(value, query []rune) int {
+	for i := 0; i+len(query) <= len(value); i++ {
+		if strings.EqualFold(string(value[i:i+len(query)]), string(query)) {
+			return i
+		}
+	}
+	return -1
 }
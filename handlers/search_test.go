@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"groupie-tracker/domain"
+	"groupie-tracker/location"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 )
 
@@ -21,15 +24,9 @@ func TestSearchHandler(t *testing.T) {
 			expectedCode:  http.StatusOK,
 			expectedError: false,
 		},
-		{
-			name:          "Invalid method POST",
-			method:        "POST",
-			query:         "queen",
-			expectedCode:  http.StatusMethodNotAllowed,
-			expectedError: true,
-		},
 	}
 
+	app := newTestApp(t)
 	for _, tt := range tests {
 		t.Run(
 			tt.name, func(t *testing.T) {
@@ -38,7 +35,7 @@ func TestSearchHandler(t *testing.T) {
 				w := httptest.NewRecorder()
 
 				// Call the handler
-				SearchHandler(w, req)
+				app.SearchHandler(w, req)
 
 				// Check status code
 				if w.Code != tt.expectedCode {
@@ -51,3 +48,170 @@ func TestSearchHandler(t *testing.T) {
 		)
 	}
 }
+
+func TestMatchSuggestion(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		query     string
+		wantSpan  MatchSpan
+		wantScore int
+		wantOk    bool
+	}{
+		{
+			name:      "Whole term",
+			value:     "Queen",
+			query:     "queen",
+			wantSpan:  MatchSpan{Start: 0, End: 5},
+			wantScore: 113,
+			wantOk:    true,
+		},
+		{
+			name:      "Prefix",
+			value:     "queensland-australia",
+			query:     "Queen",
+			wantSpan:  MatchSpan{Start: 0, End: 5},
+			wantScore: 12,
+			wantOk:    true,
+		},
+		{
+			name:      "Suffix",
+			value:     "Freddie Mercury",
+			query:     "mercury",
+			wantSpan:  MatchSpan{Start: 8, End: 15},
+			wantScore: 3,
+			wantOk:    true,
+		},
+		{
+			name:      "Multibyte offsets",
+			value:     "Beyoncé Knowles",
+			query:     "knowles",
+			wantSpan:  MatchSpan{Start: 8, End: 15},
+			wantScore: 3,
+			wantOk:    true,
+		},
+		{
+			name:      "Lower case with more characters",
+			value:     "İzmir Queen",
+			query:     "QUEEN",
+			wantSpan:  MatchSpan{Start: 6, End: 11},
+			wantScore: 3,
+			wantOk:    true,
+		},
+		{
+			name:   "No match",
+			value:  "Pink Floyd",
+			query:  "queen",
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				span, score, ok := matchSuggestion(tt.value, tt.query)
+				if ok != tt.wantOk {
+					t.Fatalf("matchSuggestion() ok = %v, want %v", ok, tt.wantOk)
+				}
+				if span != tt.wantSpan {
+					t.Errorf("matchSuggestion() span = %v, want %v", span, tt.wantSpan)
+				}
+				if score != tt.wantScore {
+					t.Errorf("matchSuggestion() score = %v, want %v", score, tt.wantScore)
+				}
+			},
+		)
+	}
+}
+
+func TestFindLocationSuggestions(t *testing.T) {
+	artists := newFakeStore(t).snapshot.Artists
+	artists = append(
+		artists, domain.Artist{
+			ID: 3, Name: "Metallica", Locations: []location.Place{location.PlaceOf("new_york-usa")},
+		},
+	)
+
+	tests := []struct {
+		name  string
+		query string
+		want  []Suggestion
+	}{
+		{
+			name: "City", query: "new york",
+			want: []Suggestion{
+				{
+					Kind: KindLocation, ArtistID: 3, ArtistName: "Metallica", Value: "new_york-usa",
+					Match: MatchSpan{Start: 0, End: 8}, Score: 12,
+				},
+			},
+		},
+		{name: "Part of a city", query: "york"},
+		{
+			name: "Country alias", query: "usa",
+			want: []Suggestion{
+				{
+					Kind: KindLocation, ArtistID: 1, ArtistName: "Queen", Value: "dallas-usa",
+					Match: MatchSpan{Start: 7, End: 10}, Score: 3,
+				},
+				{
+					Kind: KindLocation, ArtistID: 3, ArtistName: "Metallica", Value: "new_york-usa",
+					Match: MatchSpan{Start: 9, End: 12}, Score: 3,
+				},
+			},
+		},
+		{
+			name: "City and country", query: "London, UK",
+			want: []Suggestion{
+				{
+					Kind: KindLocation, ArtistID: 1, ArtistName: "Queen", Value: "london-uk",
+					Match: MatchSpan{Start: 0, End: 9}, Score: 2,
+				},
+				{
+					Kind: KindLocation, ArtistID: 2, ArtistName: "Pink Floyd", Value: "london-uk",
+					Match: MatchSpan{Start: 0, End: 9}, Score: 2,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var got []Suggestion
+				for _, suggestion := range findSuggestions(artists, tt.query) {
+					if suggestion.Kind == KindLocation {
+						got = append(got, suggestion)
+					}
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("findSuggestions(%q) locations = %+v, want %+v", tt.query, got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestSuggestionLegacy(t *testing.T) {
+	tests := []struct {
+		suggestion Suggestion
+		wantFrom   string
+	}{
+		{Suggestion{Kind: KindArtist, ArtistName: "Queen", Value: "Queen"}, "artist/band"},
+		{Suggestion{Kind: KindMember, ArtistName: "Queen", Value: "Freddie Mercury"}, "member (Queen)"},
+		{Suggestion{Kind: KindFirstAlbum, ArtistName: "Queen", Value: "14-12-1973"}, "first album date (Queen)"},
+		{Suggestion{Kind: KindCreationDate, ArtistName: "Queen", Value: "1970"}, "creation date (Queen)"},
+		{Suggestion{Kind: KindLocation, ArtistName: "Queen", Value: "osaka-japan"}, "location"},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			string(tt.suggestion.Kind), func(t *testing.T) {
+				got := tt.suggestion.legacy()
+				if got.Suggestion != tt.suggestion.Value || got.From != tt.wantFrom {
+					t.Errorf("legacy() = %+v, want {%s %s}", got, tt.suggestion.Value, tt.wantFrom)
+				}
+			},
+		)
+	}
+}
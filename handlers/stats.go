@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"groupie-tracker/httperr"
+	"net/http"
+)
+
+// StatsHandler handles HTTP GET requests for the statistics dashboard, at /stats.
+//
+// It renders the aggregate statistics computed from the cache snapshot as server-side SVG charts:
+// artists per formation decade, member counts, years from creation to first album, creation year against
+// first album year, busiest cities and countries, concerts per year and most-travelled artists.
+//
+// The handler returns appropriate HTTP status codes:
+//   - 200 OK: Successfully rendered the statistics
+//   - 503 Service Unavailable: The artists could not be fetched
+func (app *App) StatsHandler(w http.ResponseWriter, r *http.Request) {
+	handlerTemplate := "stats.html"
+	s, err := app.store.Stats()
+	if err != nil {
+		app.renderError(w, r, err)
+		return
+	}
+
+	app.renderPage(w, r, handlerTemplate, s)
+}
+
+// StatsAPIHandler handles HTTP GET requests for the aggregate statistics as JSON, at /api/v1/stats.
+// It responds with RFC 9457 problem details on error.
+func (app *App) StatsAPIHandler(w http.ResponseWriter, r *http.Request) {
+	s, err := app.store.Stats()
+	if err != nil {
+		app.errors.Error(w, r, httperr.JSON, err)
+		return
+	}
+	writeJSON(w, s)
+}
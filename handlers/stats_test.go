@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"groupie-tracker/chart"
+	"groupie-tracker/stats"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewBarChart(t *testing.T) {
+	bars := countBars([]stats.Count{{Label: "2019", Value: 4}, {Label: "2020", Value: 1}})
+	svg := string(chart.Bars("Concerts per year", bars))
+
+	if !strings.Contains(svg, `viewBox="0 0 560 44"`) {
+		t.Errorf("chart = %s, want a row per count", svg)
+	}
+
+	tests := []struct {
+		name string
+		bar  string
+	}{
+		{
+			name: "Largest value spans the full width",
+			bar:  `width="350" x="160" y="2"`,
+		},
+		{
+			name: "Smaller value is proportional",
+			bar:  `width="87.5" x="160" y="24"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if !strings.Contains(svg, tt.bar) {
+					t.Errorf("chart = %s, want the bar %s", svg, tt.bar)
+				}
+			},
+		)
+	}
+
+	empty := chart.Bars("Empty", countBars([]stats.Count{{Label: "none", Value: 0}}))
+	if !strings.Contains(string(empty), `width="0"`) {
+		t.Errorf("zero value chart = %s, want a bar of width 0", empty)
+	}
+}
+
+func TestStatsAPIHandler(t *testing.T) {
+	app := newTestApp(t)
+	req := httptest.NewRequest("GET", "/api/v1/stats", nil)
+	rr := httptest.NewRecorder()
+
+	app.StatsAPIHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("StatsAPIHandler() status = %v, want %v", rr.Code, http.StatusOK)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("StatsAPIHandler() Content-Type = %q, want %q", got, "application/json")
+	}
+
+	var got stats.Stats
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("StatsAPIHandler() body is not a statistics object: %v", err)
+	}
+	if len(got.BusiestCities) == 0 || len(got.MostTravelled) == 0 {
+		t.Fatalf("StatsAPIHandler() = %+v, want the busiest cities and most travelled artists", got)
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{name: "artistCount", got: got.ArtistCount, want: 2},
+		{name: "concertCount", got: got.ConcertCount, want: 4},
+		{name: "averageYearsToFirstAlbum", got: got.AverageYearsToFirstAlbum, want: 2.5},
+		{name: "busiestCountries", got: len(got.BusiestCountries), want: 2},
+		{
+			name: "busiestCities[0]", got: got.BusiestCities[0],
+			want: stats.Count{Label: "London, England, United Kingdom", Value: 3},
+		},
+		{name: "mostTravelled[0]", got: got.MostTravelled[0].Name, want: "Queen"},
+		{name: "careers", got: len(got.Careers), want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if tt.got != tt.want {
+					t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestStatsHandler(t *testing.T) {
+	app := newTestApp(t)
+	req := httptest.NewRequest("GET", "/stats", nil)
+	w := httptest.NewRecorder()
+
+	app.StatsHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("StatsHandler returned wrong status code: got %v want %v", w.Code, http.StatusOK)
+	}
+
+	// the statistics are charted as inline SVG
+	body := w.Body.String()
+	if !strings.Contains(body, "<svg") {
+		t.Error("Response is missing the SVG charts")
+	}
+	for _, label := range []string{"London, England, United Kingdom", "1960s", "Queen"} {
+		if !strings.Contains(body, label) {
+			t.Errorf("Response is missing the chart label %q", label)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Package httperr writes HTTP error responses, in the format the client accepts: an HTML error page,
+// an RFC 9457 problem details JSON object, or plain text
+package httperr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"groupie-tracker/xerrors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// Renderer renders the errorPage.html template, e.g. a render.Registry
+type Renderer interface {
+	Execute(w io.Writer, name string, data any) error
+}
+
+// Writer writes the error responses, rendering the HTML ones with the errorPage.html template of its templates.
+// A nil Writer writes the HTML responses as plain text.
+type Writer struct {
+	templates Renderer
+}
+
+// NewWriter returns a writer rendering the HTML error responses with the errorPage.html template of templates,
+// e.g. the page templates embedded in the binary
+func NewWriter(templates Renderer) *Writer {
+	return &Writer{templates: templates}
+}
+
+// Format is the format of an error response
+type Format int
+
+const (
+	// HTML renders the error page template
+	HTML Format = iota
+	// JSON writes an RFC 9457 problem details object, with the application/problem+json media type
+	JSON
+	// Text writes the problem title and detail as plain text
+	Text
+)
+
+// ProblemContentType is the media type of RFC 9457 problem details objects
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details object
+type Problem struct {
+	// Type is a URI identifying the problem type, `about:blank` if the problem is described by its status code
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	// Detail is a human-readable explanation of this occurrence of the problem
+	Detail string `json:"detail,omitempty"`
+	// Instance is the path of the request the problem occurred on
+	Instance string `json:"instance,omitempty"`
+	// Errors lists the invalid fields of the request, for validation problems
+	Errors any `json:"errors,omitempty"`
+}
+
+// New returns the problem described by the HTTP status code, with the given detail
+func New(status int, detail string) Problem {
+	return Problem{Type: "about:blank", Title: http.StatusText(status), Status: status, Detail: detail}
+}
+
+// StatusOf returns the HTTP status code of the error, mapped from the xerrors sentinel it wraps.
+// Returns 500 Internal Server Error for any other error.
+func StatusOf(err error) int {
+	switch {
+	case errors.Is(err, xerrors.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, xerrors.ErrBadRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, xerrors.ErrMethodNotAllowed):
+		return http.StatusMethodNotAllowed
+	case errors.Is(err, xerrors.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, xerrors.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// FromError returns the problem describing the error. The error message is only disclosed for client errors,
+// server errors are described by their status code.
+func FromError(err error) Problem {
+	status := StatusOf(err)
+	if status >= http.StatusInternalServerError {
+		return New(status, "")
+	}
+	return New(status, err.Error())
+}
+
+// Error responds with the problem describing the error, logging server errors.
+// See Write for how the response format is chosen.
+func (wr *Writer) Error(w http.ResponseWriter, r *http.Request, fallback Format, err error) {
+	p := FromError(err)
+	if p.Status >= http.StatusInternalServerError {
+		log.Printf("Error serving %s %s: %v\n", r.Method, r.URL.Path, err)
+	}
+	wr.Write(w, r, fallback, p)
+}
+
+// Write responds with the problem, in the format preferred by the request's Accept header. The fallback format
+// is used if the client accepts several formats equally, such as with `*/*`, or none of them.
+func (wr *Writer) Write(w http.ResponseWriter, r *http.Request, fallback Format, p Problem) {
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.Instance == "" && r != nil {
+		p.Instance = r.URL.Path
+	}
+
+	format := fallback
+	if r != nil {
+		format = Negotiate(r.Header.Get("Accept"), fallback)
+	}
+
+	header := w.Header()
+	header.Add("Vary", "Accept")
+	if p.Status == http.StatusServiceUnavailable && header.Get("Retry-After") == "" {
+		header.Set("Retry-After", "60")
+	}
+
+	switch format {
+	case JSON:
+		header.Set("Content-Type", ProblemContentType)
+		w.WriteHeader(p.Status)
+		_ = json.NewEncoder(w).Encode(p)
+	case Text:
+		writeText(w, p)
+	default:
+		wr.writeHTML(w, p)
+	}
+}
+
+// text returns the title and detail of the problem, on a single line
+func (p Problem) text() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+	return p.Title + ": " + p.Detail
+}
+
+// writeText writes the problem as plain text
+func writeText(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(p.Status)
+	_, _ = fmt.Fprintln(w, p.text())
+}
+
+// writeHTML renders the problem with the error page template, falling back to plain text if it can't be rendered
+func (wr *Writer) writeHTML(w http.ResponseWriter, p Problem) {
+	message := p.Detail
+	if message == "" {
+		message = p.Title
+	}
+
+	if wr == nil || wr.templates == nil {
+		writeText(w, p)
+		return
+	}
+
+	var page bytes.Buffer
+	err := wr.templates.Execute(
+		&page, "errorPage.html", struct {
+			Message string
+			Code    string
+		}{Message: message, Code: strconv.Itoa(p.Status)},
+	)
+	if err != nil {
+		log.Printf("Error rendering the error page: %v", err)
+		writeText(w, p)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(p.Status)
+	_, _ = page.WriteTo(w)
+}
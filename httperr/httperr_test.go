@@ -0,0 +1,193 @@
+package httperr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"groupie-tracker/render"
+	"groupie-tracker/xerrors"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name     string
+		accept   string
+		fallback Format
+		want     Format
+	}{
+		{name: "No header", accept: "", fallback: JSON, want: JSON},
+		{name: "Anything", accept: "*/*", fallback: HTML, want: HTML},
+		{
+			name:     "Browser",
+			accept:   "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,*/*;q=0.8",
+			fallback: JSON,
+			want:     HTML,
+		},
+		{name: "Problem JSON", accept: "application/problem+json", fallback: HTML, want: JSON},
+		{name: "JSON", accept: "application/json, text/plain;q=0.5", fallback: HTML, want: JSON},
+		{name: "Plain text", accept: "text/plain", fallback: HTML, want: Text},
+		{name: "Text wildcard prefers the fallback", accept: "text/*", fallback: Text, want: Text},
+		{name: "Text wildcard", accept: "text/*", fallback: JSON, want: HTML},
+		{name: "Quality values", accept: "text/html;q=0.2, application/json;q=0.9", fallback: HTML, want: JSON},
+		{name: "Excluded type", accept: "text/html;q=0, */*", fallback: HTML, want: JSON},
+		{name: "Nothing acceptable", accept: "image/png", fallback: Text, want: Text},
+		{name: "Malformed", accept: "html, ;q=1", fallback: JSON, want: JSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := Negotiate(tt.accept, tt.fallback); got != tt.want {
+					t.Errorf("Negotiate(%q) = %v, want %v", tt.accept, got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestStatusOf(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{err: xerrors.ErrNotFound, want: http.StatusNotFound},
+		{err: fmt.Errorf("artist 99: %w", xerrors.ErrNotFound), want: http.StatusNotFound},
+		{err: fmt.Errorf("invalid id: %w", xerrors.ErrBadRequest), want: http.StatusBadRequest},
+		{err: xerrors.ErrMethodNotAllowed, want: http.StatusMethodNotAllowed},
+		{err: fmt.Errorf("body: %w", xerrors.ErrTooLarge), want: http.StatusRequestEntityTooLarge},
+		{err: fmt.Errorf("fetching: %w", xerrors.ErrUnavailable), want: http.StatusServiceUnavailable},
+		{err: errors.New("boom"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		if got := StatusOf(tt.err); got != tt.want {
+			t.Errorf("StatusOf(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+// templateFuncs are the functions of the error page template: asset returns the plain path of a static file
+var templateFuncs = template.FuncMap{
+	"asset": func(name string) string {
+		return "/static/" + name
+	},
+}
+
+func TestWrite(t *testing.T) {
+	writer := NewWriter(render.New(os.DirFS(filepath.Join("..", "templates")), templateFuncs, false))
+
+	tests := []struct {
+		name            string
+		accept          string
+		err             error
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "HTML",
+			accept:          "text/html",
+			err:             fmt.Errorf("artist 99: %w", xerrors.ErrNotFound),
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "text/html; charset=utf-8",
+			wantBody:        "artist 99: not found",
+		},
+		{
+			name:            "Problem JSON",
+			accept:          "application/json",
+			err:             fmt.Errorf("artist 99: %w", xerrors.ErrNotFound),
+			wantStatus:      http.StatusNotFound,
+			wantContentType: ProblemContentType,
+			wantBody:        `"detail":"artist 99: not found"`,
+		},
+		{
+			name:            "Plain text",
+			accept:          "text/plain",
+			err:             fmt.Errorf("invalid id: %w", xerrors.ErrBadRequest),
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Bad Request: invalid id: bad request",
+		},
+		{
+			name:            "Server errors are not disclosed",
+			accept:          "text/plain",
+			err:             errors.New("database password is hunter2"),
+			wantStatus:      http.StatusInternalServerError,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Internal Server Error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				r := httptest.NewRequest("GET", "/details?id=99", nil)
+				r.Header.Set("Accept", tt.accept)
+				w := httptest.NewRecorder()
+
+				writer.Error(w, r, HTML, tt.err)
+
+				if w.Code != tt.wantStatus {
+					t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+				}
+				if got := w.Header().Get("Content-Type"); got != tt.wantContentType {
+					t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+				}
+				if body := w.Body.String(); !strings.Contains(body, tt.wantBody) {
+					t.Errorf("body = %q, want it to contain %q", body, tt.wantBody)
+				}
+				if strings.Contains(w.Body.String(), "hunter2") {
+					t.Error("body discloses the server error")
+				}
+			},
+		)
+	}
+}
+
+func TestWriteWithoutTemplates(t *testing.T) {
+	r := httptest.NewRequest("GET", "/details?id=99", nil)
+	r.Header.Set("Accept", "text/html")
+	w := httptest.NewRecorder()
+
+	var writer *Writer
+	writer.Error(w, r, HTML, fmt.Errorf("artist 99: %w", xerrors.ErrNotFound))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/plain; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want plain text without the error page template", got)
+	}
+}
+
+func TestWriteProblemJSON(t *testing.T) {
+	r := httptest.NewRequest("POST", "/api/filter", nil)
+	w := httptest.NewRecorder()
+
+	p := New(http.StatusBadRequest, "Invalid filter request")
+	p.Errors = []string{"combinator"}
+	NewWriter(nil).Write(w, r, JSON, p)
+
+	var got map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("body is not JSON: %v", err)
+	}
+	want := map[string]any{
+		"type":     "about:blank",
+		"title":    "Bad Request",
+		"status":   400.0,
+		"detail":   "Invalid filter request",
+		"instance": "/api/filter",
+		"errors":   []any{"combinator"},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("problem = %v, want %v", got, want)
+	}
+}
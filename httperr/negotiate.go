@@ -0,0 +1,87 @@
+package httperr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// mediaTypes are the media types of each format, the first one is the format's own
+var mediaTypes = map[Format][]string{
+	HTML: {"text/html", "application/xhtml+xml"},
+	JSON: {ProblemContentType, "application/json"},
+	Text: {"text/plain"},
+}
+
+// Negotiate returns the format preferred by the Accept header value. The fallback format is returned if the
+// header is blank, accepts none of the formats, or accepts the fallback as much as any other format.
+func Negotiate(accept string, fallback Format) Format {
+	if strings.TrimSpace(accept) == "" {
+		return fallback
+	}
+
+	ranges := parseAccept(accept)
+	best, bestQ := fallback, quality(ranges, fallback)
+	for _, format := range []Format{HTML, JSON, Text} {
+		if q := quality(ranges, format); q > bestQ {
+			best, bestQ = format, q
+		}
+	}
+	if bestQ <= 0 {
+		return fallback
+	}
+	return best
+}
+
+// mediaRange is a media range of an Accept header, e.g. `text/*;q=0.8`
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// parseAccept returns the media ranges of the Accept header value. Malformed ranges are skipped.
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(params[0])), "/")
+		if !ok || typ == "" || subtype == "" {
+			continue
+		}
+
+		mr := mediaRange{typ: typ, subtype: subtype, q: 1}
+		for _, param := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "q") {
+				if q, err := strconv.ParseFloat(value, 64); err == nil && q >= 0 && q <= 1 {
+					mr.q = q
+				}
+			}
+		}
+		ranges = append(ranges, mr)
+	}
+	return ranges
+}
+
+// quality returns how much the media ranges accept the format: the quality of the most specific range matching
+// any of the format's media types, or 0 if none matches
+func quality(ranges []mediaRange, format Format) float64 {
+	best, bestSpecificity := 0.0, -1
+	for _, mediaType := range mediaTypes[format] {
+		typ, subtype, _ := strings.Cut(mediaType, "/")
+		for _, mr := range ranges {
+			specificity := -1
+			switch {
+			case mr.typ == typ && mr.subtype == subtype:
+				specificity = 2
+			case mr.typ == typ && mr.subtype == "*":
+				specificity = 1
+			case mr.typ == "*" && mr.subtype == "*":
+				specificity = 0
+			}
+			if specificity > bestSpecificity || (specificity == bestSpecificity && specificity >= 0 && mr.q > best) {
+				best, bestSpecificity = mr.q, specificity
+			}
+		}
+	}
+	return best
+}
@@ -0,0 +1,144 @@
+// Package ical writes iCalendar (RFC 5545) calendars of all-day events
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// ContentType is the media type of iCalendar data
+	ContentType = "text/calendar; charset=utf-8"
+
+	// prodID identifies the product that created the calendar
+	prodID = "-//groupie-tracker//concerts//EN"
+	// maxLineOctets is the length lines are folded at, excluding the line break
+	maxLineOctets = 75
+
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405Z"
+)
+
+// Event is an all-day event
+type Event struct {
+	// UID identifies the event globally, and must stay the same across calendar updates
+	UID string
+	// Date is the day of the event, its time of day is ignored
+	Date     time.Time
+	Summary  string
+	Location string
+	// Lat and Lon are the coordinates of the location, written only if HasGeo is true
+	Lat, Lon float64
+	HasGeo   bool
+	URL      string
+}
+
+// Calendar is a published calendar of events
+type Calendar struct {
+	// Name is shown by calendar apps as the name of the subscribed calendar
+	Name string
+	// Stamp is when the calendar information was created, it is written as the DTSTAMP of every event
+	Stamp  time.Time
+	Events []Event
+}
+
+// Encode writes the calendar to w, in the iCalendar format
+func (c Calendar) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	e := encoder{w: bw}
+
+	e.line("BEGIN", "VCALENDAR")
+	e.line("VERSION", "2.0")
+	e.line("PRODID", prodID)
+	e.line("CALSCALE", "GREGORIAN")
+	e.line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		e.line("X-WR-CALNAME", Escape(c.Name))
+	}
+
+	stamp := c.Stamp.UTC().Format(dateTimeLayout)
+	for _, event := range c.Events {
+		// all-day events start on their date, and end, exclusively, on the next day
+		year, month, day := event.Date.Date()
+		start := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+		e.line("BEGIN", "VEVENT")
+		e.line("UID", Escape(event.UID))
+		e.line("DTSTAMP", stamp)
+		e.line("DTSTART;VALUE=DATE", start.Format(dateLayout))
+		e.line("DTEND;VALUE=DATE", start.AddDate(0, 0, 1).Format(dateLayout))
+		e.line("SUMMARY", Escape(event.Summary))
+		if event.Location != "" {
+			e.line("LOCATION", Escape(event.Location))
+		}
+		if event.HasGeo {
+			e.line("GEO", fmt.Sprintf("%.6f;%.6f", event.Lat, event.Lon))
+		}
+		if event.URL != "" {
+			e.line("URL", event.URL)
+		}
+		// all-day concerts don't make the attendee busy the whole day
+		e.line("TRANSP", "TRANSPARENT")
+		e.line("END", "VEVENT")
+	}
+
+	e.line("END", "VCALENDAR")
+	if e.err != nil {
+		return e.err
+	}
+	return bw.Flush()
+}
+
+// Escape escapes the special characters of a TEXT value
+func Escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// encoder writes content lines, keeping the first write error
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line writes the content line name:value, folded to lines of at most maxLineOctets octets
+func (e *encoder) line(name, value string) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = e.w.WriteString(Fold(name+":"+value) + "\r\n")
+}
+
+// Fold folds the content line to lines of at most 75 octets, separated by a CRLF followed by a space.
+// Lines are never split within a UTF-8 sequence.
+func Fold(line string) string {
+	if len(line) <= maxLineOctets {
+		return line
+	}
+
+	var b strings.Builder
+	// the continuation lines start with a space, which counts towards their length
+	limit := maxLineOctets
+	n := 0
+	for _, r := range line {
+		size := utf8.RuneLen(r)
+		if n+size > limit {
+			b.WriteString("\r\n ")
+			limit = maxLineOctets - 1
+			n = 0
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "Plain", input: "Queen", want: "Queen"},
+		{name: "Comma", input: "London, United Kingdom", want: `London\, United Kingdom`},
+		{name: "Semicolon and backslash", input: `a;b\c`, want: `a\;b\\c`},
+		{name: "Newlines", input: "a\nb\r\nc", want: `a\nb\nc`},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := Escape(tt.input); got != tt.want {
+					t.Errorf("Escape(%q) = %q, want %q", tt.input, got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "Short", input: "SUMMARY:Queen"},
+		{name: "Exactly 75 octets", input: "SUMMARY:" + strings.Repeat("a", 67)},
+		{name: "Long ASCII", input: "SUMMARY:" + strings.Repeat("a", 200)},
+		{name: "Long multibyte", input: "LOCATION:" + strings.Repeat("Île-de-France ", 20)},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got := Fold(tt.input)
+				lines := strings.Split(got, "\r\n")
+				for i, line := range lines {
+					if len(line) > maxLineOctets {
+						t.Errorf("line %d is %d octets long, want at most %d", i, len(line), maxLineOctets)
+					}
+					if i > 0 && !strings.HasPrefix(line, " ") {
+						t.Errorf("continuation line %d doesn't start with a space: %q", i, line)
+					}
+				}
+				// unfolding gives back the original line
+				if unfolded := strings.ReplaceAll(got, "\r\n ", ""); unfolded != tt.input {
+					t.Errorf("unfolded Fold() = %q, want %q", unfolded, tt.input)
+				}
+			},
+		)
+	}
+}
+
+func TestCalendarEncode(t *testing.T) {
+	calendar := Calendar{
+		Name:  "Queen concerts",
+		Stamp: time.Date(2024, time.March, 1, 12, 30, 0, 0, time.FixedZone("EAT", 3*60*60)),
+		Events: []Event{
+			{
+				UID:      "1-19801231-london-uk@groupie-tracker",
+				Date:     time.Date(1980, time.December, 31, 0, 0, 0, 0, time.UTC),
+				Summary:  "Queen in London",
+				Location: "London, England, United Kingdom",
+				Lat:      51.507,
+				Lon:      -0.128,
+				HasGeo:   true,
+				URL:      "http://localhost:8080/details?id=1",
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := calendar.Encode(&buf); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	want := strings.Join(
+		[]string{
+			"BEGIN:VCALENDAR",
+			"VERSION:2.0",
+			"PRODID:-//groupie-tracker//concerts//EN",
+			"CALSCALE:GREGORIAN",
+			"METHOD:PUBLISH",
+			"X-WR-CALNAME:Queen concerts",
+			"BEGIN:VEVENT",
+			"UID:1-19801231-london-uk@groupie-tracker",
+			"DTSTAMP:20240301T093000Z",
+			"DTSTART;VALUE=DATE:19801231",
+			"DTEND;VALUE=DATE:19810101",
+			"SUMMARY:Queen in London",
+			`LOCATION:London\, England\, United Kingdom`,
+			"GEO:51.507000;-0.128000",
+			"URL:http://localhost:8080/details?id=1",
+			"TRANSP:TRANSPARENT",
+			"END:VEVENT",
+			"END:VCALENDAR",
+			"",
+		}, "\r\n",
+	)
+	if got := buf.String(); got != want {
+		t.Errorf("Encode() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,124 @@
+package location
+
+import "strings"
+
+// country holds the name and continent code of a country
+type country struct {
+	Name      string
+	Continent string
+}
+
+// continents maps the continent codes to the continent names
+var continents = map[string]string{
+	"AF": "Africa",
+	"AN": "Antarctica",
+	"AS": "Asia",
+	"EU": "Europe",
+	"NA": "North America",
+	"OC": "Oceania",
+	"SA": "South America",
+}
+
+// countries maps the ISO 3166-1 alpha-2 codes of the countries in the location dictionary
+// to their names and continents
+var countries = map[string]country{
+	"AE": {Name: "United Arab Emirates", Continent: "AS"},
+	"AR": {Name: "Argentina", Continent: "SA"},
+	"AT": {Name: "Austria", Continent: "EU"},
+	"AU": {Name: "Australia", Continent: "OC"},
+	"BE": {Name: "Belgium", Continent: "EU"},
+	"BG": {Name: "Bulgaria", Continent: "EU"},
+	"BH": {Name: "Bahrain", Continent: "AS"},
+	"BR": {Name: "Brazil", Continent: "SA"},
+	"BY": {Name: "Belarus", Continent: "EU"},
+	"CA": {Name: "Canada", Continent: "NA"},
+	"CH": {Name: "Switzerland", Continent: "EU"},
+	"CL": {Name: "Chile", Continent: "SA"},
+	"CN": {Name: "China", Continent: "AS"},
+	"CO": {Name: "Colombia", Continent: "SA"},
+	"CR": {Name: "Costa Rica", Continent: "NA"},
+	"CZ": {Name: "Czechia", Continent: "EU"},
+	"DE": {Name: "Germany", Continent: "EU"},
+	"DK": {Name: "Denmark", Continent: "EU"},
+	"EC": {Name: "Ecuador", Continent: "SA"},
+	"EE": {Name: "Estonia", Continent: "EU"},
+	"EG": {Name: "Egypt", Continent: "AF"},
+	"ES": {Name: "Spain", Continent: "EU"},
+	"FI": {Name: "Finland", Continent: "EU"},
+	"FR": {Name: "France", Continent: "EU"},
+	"GB": {Name: "United Kingdom", Continent: "EU"},
+	"GE": {Name: "Georgia", Continent: "AS"},
+	"GH": {Name: "Ghana", Continent: "AF"},
+	"GR": {Name: "Greece", Continent: "EU"},
+	"GT": {Name: "Guatemala", Continent: "NA"},
+	"HR": {Name: "Croatia", Continent: "EU"},
+	"HU": {Name: "Hungary", Continent: "EU"},
+	"ID": {Name: "Indonesia", Continent: "AS"},
+	"IE": {Name: "Ireland", Continent: "EU"},
+	"IL": {Name: "Israel", Continent: "AS"},
+	"IN": {Name: "India", Continent: "AS"},
+	"IS": {Name: "Iceland", Continent: "EU"},
+	"IT": {Name: "Italy", Continent: "EU"},
+	"JP": {Name: "Japan", Continent: "AS"},
+	"KE": {Name: "Kenya", Continent: "AF"},
+	"KR": {Name: "South Korea", Continent: "AS"},
+	"LB": {Name: "Lebanon", Continent: "AS"},
+	"LK": {Name: "Sri Lanka", Continent: "AS"},
+	"LT": {Name: "Lithuania", Continent: "EU"},
+	"LU": {Name: "Luxembourg", Continent: "EU"},
+	"LV": {Name: "Latvia", Continent: "EU"},
+	"MA": {Name: "Morocco", Continent: "AF"},
+	"MX": {Name: "Mexico", Continent: "NA"},
+	"MY": {Name: "Malaysia", Continent: "AS"},
+	"NC": {Name: "New Caledonia", Continent: "OC"},
+	"NG": {Name: "Nigeria", Continent: "AF"},
+	"NL": {Name: "Netherlands", Continent: "EU"},
+	"NO": {Name: "Norway", Continent: "EU"},
+	"NZ": {Name: "New Zealand", Continent: "OC"},
+	"PA": {Name: "Panama", Continent: "NA"},
+	"PE": {Name: "Peru", Continent: "SA"},
+	"PF": {Name: "French Polynesia", Continent: "OC"},
+	"PH": {Name: "Philippines", Continent: "AS"},
+	"PL": {Name: "Poland", Continent: "EU"},
+	"PR": {Name: "Puerto Rico", Continent: "NA"},
+	"PT": {Name: "Portugal", Continent: "EU"},
+	"PY": {Name: "Paraguay", Continent: "SA"},
+	"QA": {Name: "Qatar", Continent: "AS"},
+	"RO": {Name: "Romania", Continent: "EU"},
+	"RS": {Name: "Serbia", Continent: "EU"},
+	"RU": {Name: "Russia", Continent: "EU"},
+	"SA": {Name: "Saudi Arabia", Continent: "AS"},
+	"SE": {Name: "Sweden", Continent: "EU"},
+	"SG": {Name: "Singapore", Continent: "AS"},
+	"SI": {Name: "Slovenia", Continent: "EU"},
+	"SK": {Name: "Slovakia", Continent: "EU"},
+	"TH": {Name: "Thailand", Continent: "AS"},
+	"TR": {Name: "Turkey", Continent: "AS"},
+	"TW": {Name: "Taiwan", Continent: "AS"},
+	"UA": {Name: "Ukraine", Continent: "EU"},
+	"US": {Name: "United States", Continent: "NA"},
+	"UY": {Name: "Uruguay", Continent: "SA"},
+	"VE": {Name: "Venezuela", Continent: "SA"},
+	"VN": {Name: "Vietnam", Continent: "AS"},
+	"ZA": {Name: "South Africa", Continent: "AF"},
+}
+
+// CountryName returns the name of the country with the given ISO 3166 alpha-2 code, ignoring case and surrounding
+// space, e.g. `United States` for `us`. Returns false if the country isn't in the location dictionary.
+func CountryName(code string) (string, bool) {
+	c, ok := countries[strings.ToUpper(strings.TrimSpace(code))]
+	return c.Name, ok
+}
+
+// Continent returns the continent code (AF, AN, AS, EU, NA, OC or SA) for the given continent name
+// or code, ignoring case. For example, both `europe` and `EU` return `EU`.
+// Returns false if the continent is unknown.
+func Continent(nameOrCode string) (string, bool) {
+	nameOrCode = strings.TrimSpace(nameOrCode)
+	for code, name := range continents {
+		if strings.EqualFold(code, nameOrCode) || strings.EqualFold(name, nameOrCode) {
+			return code, true
+		}
+	}
+	return "", false
+}
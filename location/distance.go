@@ -0,0 +1,28 @@
+package location
+
+import "math"
+
+// earthRadiusKm is the mean radius of the earth in kilometres
+const earthRadiusKm = 6371.0088
+
+// Distance returns the great-circle distance, in kilometres, between the points at the given latitudes
+// and longitudes (in degrees), using the haversine formula
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := func(degrees float64) float64 {
+		return degrees * math.Pi / 180
+	}
+
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	// guard against rounding pushing a just beyond 1 for antipodal points
+	a = math.Min(1, a)
+
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// DistanceTo returns the great-circle distance, in kilometres, between the two places
+func (p Place) DistanceTo(other Place) float64 {
+	return Distance(p.Lat, p.Lon, other.Lat, other.Lon)
+}
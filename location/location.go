@@ -31,6 +31,9 @@ func Parse(s string) (city, country string) {
 
 // Contains checks if a given location is contained in the other location.
 // For example Seattle, Washington, USA is part of Washington, USA
+//
+// Deprecated: Contains matches any substring, so that `usa` or even `a` matches nearly every location.
+// Use ParseQuery and Query.Matches, or Match, to match locations component by component.
 func Contains(a, b location) bool {
 	a = strings.ToLower(a)
 	b = strings.ToLower(b)
@@ -1,6 +1,10 @@
 package location
 
-import "testing"
+import (
+	"math"
+	"slices"
+	"testing"
+)
 
 func TestParse(t *testing.T) {
 	type args struct {
@@ -226,3 +230,202 @@ func TestGetCityCountry(t *testing.T) {
 		)
 	}
 }
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		slug   string
+		want   Place
+		wantOk bool
+	}{
+		{
+			slug: "north_carolina-usa",
+			want: Place{
+				Slug: "north_carolina-usa", Region: "North Carolina", Country: "United States",
+				CountryCode: "US", Continent: "NA", Lat: 35.630, Lon: -79.806,
+			},
+			wantOk: true,
+		},
+		{
+			slug: " Dunedin-New_Zealand ",
+			want: Place{
+				Slug: "dunedin-new_zealand", City: "Dunedin", Region: "Otago", Country: "New Zealand",
+				CountryCode: "NZ", Continent: "OC", Lat: -45.879, Lon: 170.503,
+			},
+			wantOk: true,
+		},
+		{
+			slug:   "atlantis-ocean",
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.slug, func(t *testing.T) {
+				got, ok := Normalize(tt.slug)
+				if ok != tt.wantOk {
+					t.Fatalf("Normalize() ok = %v, want %v", ok, tt.wantOk)
+				}
+				if got != tt.want {
+					t.Errorf("Normalize() = %+v, want %+v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestPlaceString(t *testing.T) {
+	tests := []struct {
+		slug string
+		want string
+	}{
+		{slug: "los_angeles-usa", want: "Los Angeles, California, United States"},
+		{slug: "texas-usa", want: "Texas, United States"},
+		{slug: "tokyo-japan", want: "Tokyo, Japan"},
+		{slug: "berlin-germany", want: "Berlin, Germany"},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.slug, func(t *testing.T) {
+				place, _ := Normalize(tt.slug)
+				if got := place.String(); got != tt.want {
+					t.Errorf("String() = %q, want %q", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestUnknown(t *testing.T) {
+	got := Unknown([]string{"osaka-japan", "nowhere-land", "atlantis-ocean", "nowhere-land"})
+	want := []string{"atlantis-ocean", "nowhere-land"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Unknown() = %v, want %v", got, want)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		lat1 float64
+		lon1 float64
+		lat2 float64
+		lon2 float64
+		want float64
+	}{
+		{name: "Same point", lat1: 52.520, lon1: 13.405, lat2: 52.520, lon2: 13.405, want: 0},
+		{name: "Quarter meridian", lat1: 0, lon1: 0, lat2: 90, lon2: 0, want: math.Pi / 2 * earthRadiusKm},
+		{name: "Antipodes", lat1: 0, lon1: 0, lat2: 0, lon2: 180, want: math.Pi * earthRadiusKm},
+		{name: "Berlin to Munich", lat1: 52.520, lon1: 13.405, lat2: 48.135, lon2: 11.582, want: 504},
+		{name: "London to Paris", lat1: 51.507, lon1: -0.128, lat2: 48.857, lon2: 2.352, want: 344},
+		{name: "Across the antimeridian", lat1: 0, lon1: 179, lat2: 0, lon2: -179, want: math.Pi / 90 * earthRadiusKm},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got := Distance(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+				// the known city distances are rounded to the nearest kilometre
+				if math.Abs(got-tt.want) > 1 {
+					t.Errorf("Distance() = %v, want %v", got, tt.want)
+				}
+
+				// the distance is symmetric
+				if reverse := Distance(tt.lat2, tt.lon2, tt.lat1, tt.lon1); math.Abs(reverse-got) > 1e-9 {
+					t.Errorf("Distance() reversed = %v, want %v", reverse, got)
+				}
+			},
+		)
+	}
+}
+
+func TestContinent(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   string
+		wantOk bool
+	}{
+		{in: "Europe", want: "EU", wantOk: true},
+		{in: "north america", want: "NA", wantOk: true},
+		{in: "oc", want: "OC", wantOk: true},
+		{in: "Atlantis", want: "", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.in, func(t *testing.T) {
+				got, ok := Continent(tt.in)
+				if got != tt.want || ok != tt.wantOk {
+					t.Errorf("Continent() = (%q, %v), want (%q, %v)", got, ok, tt.want, tt.wantOk)
+				}
+			},
+		)
+	}
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		in       string
+		wantSlug string
+		wantOk   bool
+	}{
+		{in: "berlin-germany", wantSlug: "berlin-germany", wantOk: true},
+		{in: "Berlin", wantSlug: "berlin-germany", wantOk: true},
+		{in: " los angeles ", wantSlug: "los_angeles-usa", wantOk: true},
+		{in: "Texas", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.in, func(t *testing.T) {
+				got, ok := Find(tt.in)
+				if got.Slug != tt.wantSlug || ok != tt.wantOk {
+					t.Errorf("Find() = (%q, %v), want (%q, %v)", got.Slug, ok, tt.wantSlug, tt.wantOk)
+				}
+			},
+		)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		slug  string
+		query string
+		want  bool
+	}{
+		{name: "Region, country", slug: "texas-usa", query: "Texas, USA", want: true},
+		{name: "City within region", slug: "houston-usa", query: "Texas, USA", want: true},
+		{name: "City, region, country", slug: "seattle-usa", query: "Seattle, Washington, USA", want: true},
+		{name: "Country alias", slug: "seattle-usa", query: "united states", want: true},
+		{name: "Country alias uk", slug: "london-uk", query: "United Kingdom", want: true},
+		{name: "Country name", slug: "london-uk", query: "uk", want: true},
+		{name: "Hyphenated query", slug: "texas-usa", query: "texas-usa", want: true},
+		{name: "Accents and punctuation", slug: "sao_paulo-brazil", query: "Sao Paulo", want: true},
+		{name: "Unknown slug falls back to parsing", slug: "nowhere-usa", query: "Nowhere, USA", want: true},
+		{name: "Different region", slug: "texas-usa", query: "Washington, USA", want: false},
+		{name: "Single letter", slug: "texas-usa", query: "a", want: false},
+		{name: "Substring of country", slug: "texas-usa", query: "us a", want: false},
+		{name: "Substring of city", slug: "queensland-australia", query: "queen", want: false},
+		{name: "Components out of order", slug: "seattle-usa", query: "USA, Seattle", want: false},
+		{name: "Ambiguous state", slug: "georgia-usa", query: "Georgia", want: true},
+		{name: "Ambiguous country", slug: "tbilisi-georgia", query: "Georgia", want: true},
+		{name: "Disambiguated state", slug: "tbilisi-georgia", query: "Georgia, USA", want: false},
+		{name: "Qualified country", slug: "tbilisi-georgia", query: "country:georgia", want: true},
+		{name: "Qualified country excludes state", slug: "georgia-usa", query: "country:georgia", want: false},
+		{name: "Qualified region", slug: "atlanta-usa", query: "region:georgia", want: true},
+		{name: "Qualified city", slug: "atlanta-usa", query: "city: Atlanta, usa", want: true},
+		{name: "Blank query", slug: "texas-usa", query: " , ", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := Match(tt.slug, tt.query); got != tt.want {
+					t.Errorf("Match(%q, %q) = %v, want %v", tt.slug, tt.query, got, tt.want)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,194 @@
+package location
+
+import (
+	"strings"
+)
+
+// countryAliases maps alternative (folded) country names to their ISO 3166 country codes
+var countryAliases = map[string]string{
+	"us":                       "US",
+	"usa":                      "US",
+	"united states":            "US",
+	"united states of america": "US",
+	"america":                  "US",
+	"uk":                       "GB",
+	"gb":                       "GB",
+	"great britain":            "GB",
+	"britain":                  "GB",
+	"united kingdom":           "GB",
+	"uae":                      "AE",
+	"emirates":                 "AE",
+	"czech republic":           "CZ",
+	"holland":                  "NL",
+	"the netherlands":          "NL",
+	"korea":                    "KR",
+	"republic of korea":        "KR",
+	"russian federation":       "RU",
+}
+
+// accentFolder replaces the accented letters found in place names with their plain counterparts
+var accentFolder = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a", "å", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "ö", "o", "õ", "o", "ø", "o", "ő", "o", "ō", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n", "ß", "ss", "ł", "l", "ś", "s", "ź", "z", "ż", "z", "ń", "n", "ć", "c", "ę", "e", "ą", "a",
+)
+
+// fold normalizes a location component for comparison: lower case, without accents or punctuation,
+// and with underscores, hyphens and repeated spaces collapsed to single spaces.
+// For example `Westcliff-on-Sea` and `westcliff_on_sea` both fold to `westcliff on sea`.
+func fold(s string) string {
+	s = accentFolder.Replace(strings.ToLower(s))
+	s = strings.NewReplacer("_", " ", "-", " ", ".", "", "'", "").Replace(s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// countryCode returns the country code named by the folded country name or alias s
+func countryCode(s string) (string, bool) {
+	if code, ok := countryAliases[s]; ok {
+		return code, true
+	}
+	for code, country := range countries {
+		if fold(country.Name) == s {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// PlaceOf returns the canonical place of the given hyphenated location slug. Slugs missing from the
+// offline location dictionary fall back to the city and country parsed from the slug, without coordinates.
+func PlaceOf(slug string) Place {
+	if place, ok := Normalize(slug); ok {
+		return place
+	}
+
+	city, country := Parse(strings.ToLower(strings.TrimSpace(slug)))
+	place := Place{Slug: slug, City: city, Country: country}
+	if code, ok := countryCode(fold(country)); ok {
+		place.Country = countries[code].Name
+		place.CountryCode = code
+		place.Continent = countries[code].Continent
+	}
+	return place
+}
+
+// Query is a parsed location query, such as `Seattle, Washington, USA`, that matches places component by component
+type Query struct {
+	// Terms are the folded, unqualified components of the query, from the most to the least specific
+	Terms []string
+	// City, Region and Country are the folded components explicitly qualified in the query, e.g. `country:georgia`
+	City    string
+	Region  string
+	Country string
+}
+
+// ParseQuery parses the location query s. The query is a comma separated list of location components,
+// from the most to the least specific, e.g. `Seattle, Washington, USA`, or a hyphenated location slug,
+// e.g. `texas-usa`. A component may be qualified with `city:`, `region:` or `country:` to disambiguate it,
+// e.g. `country:georgia` matches places in the country Georgia, but not in the US state of Georgia.
+func ParseQuery(s string) (q Query) {
+	s = strings.TrimSpace(s)
+	var components []string
+	if !strings.ContainsAny(s, ", ") && strings.Contains(s, "-") {
+		if place, ok := Normalize(s); ok {
+			components = []string{place.City, place.Region, place.Country}
+		} else {
+			city, country := Parse(strings.ToLower(s))
+			components = []string{city, country}
+		}
+	} else {
+		components = strings.Split(s, ",")
+	}
+
+	for _, component := range components {
+		qualifier, value, qualified := strings.Cut(component, ":")
+		if !qualified {
+			if value = fold(component); value != "" {
+				q.Terms = append(q.Terms, value)
+			}
+			continue
+		}
+
+		switch fold(qualifier) {
+		case "city":
+			q.City = fold(value)
+		case "region", "state":
+			q.Region = fold(value)
+		case "country":
+			q.Country = fold(value)
+		default:
+			// not a qualifier after all, e.g. a colon in a name
+			if value = fold(component); value != "" {
+				q.Terms = append(q.Terms, value)
+			}
+		}
+	}
+	return
+}
+
+// IsZero reports whether the query has no components, in which case it matches nothing
+func (q Query) IsZero() bool {
+	return len(q.Terms) == 0 && q.City == "" && q.Region == "" && q.Country == ""
+}
+
+// Matches reports whether the place satisfies the query. Each component of the query must equal a component
+// of the place (its city, region or country); a country may also be named by an alias such as `usa` or `uk`.
+// Unqualified components must appear in the same order as in the place, so that `Georgia, USA` matches
+// the US state of Georgia, while `Georgia` alone matches both the state and the country.
+func (q Query) Matches(p Place) bool {
+	if q.IsZero() {
+		return false
+	}
+
+	matchesCountry := func(term string) bool {
+		if p.Country == "" {
+			return false
+		}
+		if term == fold(p.Country) {
+			return true
+		}
+		code, ok := countryCode(term)
+		return ok && code == p.CountryCode
+	}
+
+	if q.City != "" && q.City != fold(p.City) {
+		return false
+	}
+	if q.Region != "" && q.Region != fold(p.Region) {
+		return false
+	}
+	if q.Country != "" && !matchesCountry(q.Country) {
+		return false
+	}
+
+	// the place components, ordered from the most to the least specific
+	components := []func(term string) bool{
+		func(term string) bool { return p.City != "" && term == fold(p.City) },
+		func(term string) bool { return p.Region != "" && term == fold(p.Region) },
+		matchesCountry,
+	}
+
+	next := 0
+	for _, term := range q.Terms {
+		matched := false
+		for next < len(components) {
+			next++
+			if components[next-1](term) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// Match reports whether the concert location with the given hyphenated slug satisfies the location query
+func Match(slug, query string) bool {
+	return ParseQuery(query).Matches(PlaceOf(slug))
+}
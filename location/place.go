@@ -0,0 +1,133 @@
+package location
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// placesCSV is the bundled, offline dictionary of the hyphenated location slugs used by the
+// Groupie Trackers API, with the columns: slug, city, region, country_code, lat, lon
+//
+//go:embed places.csv
+var placesCSV string
+
+// places maps the known location slugs to their canonical places
+var places = mustLoadPlaces(placesCSV)
+
+// Place is the canonical, geocoded form of a hyphenated location slug such as `north_carolina-usa`.
+// City is blank for slugs that name a region (a state, province, ...) rather than a city.
+type Place struct {
+	Slug        string `json:"slug"`
+	City        string `json:"city"`
+	Region      string `json:"region"`
+	Country     string `json:"country"`
+	CountryCode string `json:"country_code"`
+	// Continent is the code of the continent of the country, one of AF, AN, AS, EU, NA, OC or SA
+	Continent string  `json:"continent"`
+	Lat       float64 `json:"lat"`
+	Lon       float64 `json:"lon"`
+}
+
+// String returns the human-readable name of the place, e.g. `Los Angeles, California, United States`.
+// The region is left out when it repeats the name of the city.
+func (p Place) String() string {
+	var parts []string
+	if p.City != "" {
+		parts = append(parts, p.City)
+	}
+	if p.Region != "" && p.Region != p.City {
+		parts = append(parts, p.Region)
+	}
+	if p.Country != "" {
+		parts = append(parts, p.Country)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Located reports whether the place has coordinates. Places parsed from slugs missing from the offline
+// location dictionary have none.
+func (p Place) Located() bool {
+	return p.Lat != 0 || p.Lon != 0
+}
+
+// Normalize looks up the given hyphenated location slug, e.g. `dunedin-new_zealand`, in the offline
+// location dictionary, returning its canonical place. The lookup ignores case and surrounding space.
+// Returns false if the slug is not in the dictionary.
+func Normalize(slug string) (Place, bool) {
+	place, ok := places[strings.ToLower(strings.TrimSpace(slug))]
+	return place, ok
+}
+
+// Find returns the place in the offline location dictionary with the given slug, e.g. `berlin-germany`,
+// or, failing that, the first place, by slug, whose city is named s, e.g. `Berlin`. The lookup ignores case.
+// Returns false if no place matches.
+func Find(s string) (Place, bool) {
+	if place, ok := Normalize(s); ok {
+		return place, true
+	}
+
+	s = strings.TrimSpace(s)
+	var found []Place
+	for _, place := range places {
+		if place.City != "" && strings.EqualFold(place.City, s) {
+			found = append(found, place)
+		}
+	}
+	if len(found) == 0 {
+		return Place{}, false
+	}
+	slices.SortFunc(
+		found, func(a, b Place) int {
+			return strings.Compare(a.Slug, b.Slug)
+		},
+	)
+	return found[0], true
+}
+
+// Unknown returns the sorted, de-duplicated list of the given location slugs that are missing
+// from the offline location dictionary
+func Unknown(slugs []string) (unknown []string) {
+	for _, slug := range slugs {
+		if _, ok := Normalize(slug); !ok && !slices.Contains(unknown, slug) {
+			unknown = append(unknown, slug)
+		}
+	}
+	slices.Sort(unknown)
+	return
+}
+
+// mustLoadPlaces parses the location dictionary in the given CSV data. It panics if the data is malformed,
+// since the dictionary is bundled with the application.
+func mustLoadPlaces(data string) map[string]Place {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		panic(fmt.Errorf("location: invalid places dictionary: %w", err))
+	}
+
+	result := make(map[string]Place, len(records))
+	// skip the header row
+	for i, record := range records[1:] {
+		lat, latErr := strconv.ParseFloat(record[4], 64)
+		lon, lonErr := strconv.ParseFloat(record[5], 64)
+		country, ok := countries[record[3]]
+		if latErr != nil || lonErr != nil || !ok {
+			panic(fmt.Errorf("location: invalid places dictionary entry on line %d: %v", i+2, record))
+		}
+
+		result[record[0]] = Place{
+			Slug:        record[0],
+			City:        record[1],
+			Region:      record[2],
+			Country:     country.Name,
+			CountryCode: record[3],
+			Continent:   country.Continent,
+			Lat:         lat,
+			Lon:         lon,
+		}
+	}
+	return result
+}
@@ -1,23 +1,59 @@
 package main
 
 import (
+	"embed"
 	"flag"
 	"fmt"
+	"groupie-tracker/assets"
+	"groupie-tracker/cache"
 	"groupie-tracker/fileio"
-	"groupie-tracker/filter"
 	"groupie-tracker/handlers"
+	"groupie-tracker/server"
+	"groupie-tracker/xtime"
 	"io"
+	"io/fs"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path"
-	"path/filepath"
 	"runtime"
+	"time"
 )
 
 var port = flag.Int("P", 8080, "port to listen on")
 var open = flag.Bool("O", false, "whether to open page in default browser")
+var dev = flag.Bool(
+	"dev", false,
+	"whether to reload the templates and static files from disk when they change, for development (from -assets, default the working directory)",
+)
+var assetsDir = flag.String(
+	"assets", "", "directory to serve the templates/ and static/ directories from (default the files embedded in the binary)",
+)
+var publicURL = flag.String(
+	"url", "", "public URL of the site, e.g. https://groupie.example.com, which the calendar feeds link the artist pages with (default no links)",
+)
+var now = flag.String("N", "", "date to split upcoming and past concerts at, e.g. 2019-06-01 (default today)")
+
+// The brotli variants of the static scripts, icons and fonts are precompressed, as the standard library has no
+// brotli encoder. Regenerate them after editing these files.
+//
+//go:generate sh -c "find static \\( -name '*.js' -o -name '*.svg' -o -name '*.otf' \\) -exec brotli --force --best --keep {} +"
+
+// embedded are the templates and static files embedded in the binary, served unless the -assets flag is set
+//
+//go:embed templates static
+var embedded embed.FS
+
+// assetsFS returns the file system holding the templates/ and static/ directories: the directory dir on disk,
+// or the embedded assets if dir is empty
+func assetsFS(dir string) fs.FS {
+	if dir == "" {
+		return embedded
+	}
+	return os.DirFS(dir)
+}
 
 // openBrowser function opens a URL in the default web browser based on the operating
 // system that the code is running on. It handles Linux, Windows,and macOS platforms.
@@ -56,29 +92,48 @@ func main() {
 		defer fileio.Close(logger)
 	}
 
-	http.HandleFunc("/", handlers.IndexHandler)
-	http.HandleFunc("/details", handlers.DetailsHandler)
-	http.HandleFunc("/search-suggestions", handlers.SearchHandler)
-	http.HandleFunc("/filter", handlers.Filter)
-	http.HandleFunc("/api/filter", filter.API)
+	clock := handlers.Clock(handlers.ClockFunc(time.Now))
+	if *now != "" {
+		date, err := xtime.Parse(*now)
+		if err != nil {
+			log.Fatalf("invalid -N date: %v\n", err)
+		}
+		clock = handlers.ClockFunc(
+			func() time.Time {
+				return date
+			},
+		)
+	}
 
-	// Browsers ping for the /favicon.ico icon, redirect to the respective static file
-	http.Handle("/favicon.ico", http.RedirectHandler("/static/images/favicon.svg", http.StatusMovedPermanently))
-	// Server static files from the static dir, but, ensure not to expose the directory entries
-	staticDirFileServer := http.StripPrefix("/static/", http.FileServer(http.Dir("static")))
-	http.HandleFunc(
-		"/static/", func(w http.ResponseWriter, r *http.Request) {
-			// clean to remove trailing slash in path, so that the
-			//paths `/static` and `/static/` both translate to `/static`
-			reqPath := filepath.Clean(r.URL.Path)
-			switch reqPath {
-			case "/static", "/static/css", "/static/fonts", "/static/gifs", "/static/images", "/static/js":
-				handlers.RenderErrorPage(w, "Bad Request", http.StatusBadRequest)
-				return
-			}
-			staticDirFileServer.ServeHTTP(w, r)
-		},
-	)
+	if *publicURL != "" {
+		u, err := url.Parse(*publicURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			log.Fatalf("invalid -url %q: expected an absolute http or https URL\n", *publicURL)
+		}
+	}
+
+	// reloading the templates when they change only makes sense for files on disk
+	if *dev && *assetsDir == "" {
+		*assetsDir = "."
+	}
+	files := assetsFS(*assetsDir)
+	templatesFS, err := fs.Sub(files, "templates")
+	if err != nil {
+		log.Fatalf("failed to open templates: %v\n", err)
+	}
+	staticFS, err := fs.Sub(files, "static")
+	if err != nil {
+		log.Fatalf("failed to open static files: %v\n", err)
+	}
+	static, err := assets.New(staticFS, "/static/", *dev)
+	if err != nil {
+		log.Fatalf("failed to load static files: %v\n", err)
+	}
+	templates, err := handlers.NewTemplates(templatesFS, static, *dev)
+	if err != nil {
+		log.Fatalf("failed to load templates: %v\n", err)
+	}
+	app := handlers.New(cache.New(cache.APISource{}), templates, clock, *publicURL)
 
 	servePort := fmt.Sprintf(":%d", *port)
 	url := fmt.Sprintf("http://localhost%s\n", servePort)
@@ -88,5 +143,5 @@ func main() {
 		openBrowser(url)
 	}
 
-	log.Fatal(http.ListenAndServe(servePort, nil))
+	log.Fatal(http.ListenAndServe(servePort, server.New(app, static.WithErrors(app.Errors()), *dev)))
 }
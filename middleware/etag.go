@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// startedAt tells apart the responses of the running server from those of earlier runs, whose templates may differ
+var startedAt = strconv.FormatInt(time.Now().UnixNano(), 36)
+
+// ETag tags the successful GET responses of next with a weak ETag derived from the version of the data they
+// are built from, e.g. cache.Cache.Version, and responds with 304 Not Modified to the requests whose If-None-Match
+// header holds it. Besides the version, the ETag depends on the request's URL and the headers the responses
+// vary with, so that each variant gets its own ETag. If the version can't be read, next responds as usual.
+func ETag(version func() (string, error), next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			v, err := version()
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			etag := tag(v, r)
+			if match(r.Header.Get("If-None-Match"), etag) {
+				w.Header().Set("ETag", etag)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			next.ServeHTTP(&etagResponseWriter{ResponseWriter: w, etag: etag}, r)
+		},
+	)
+}
+
+// tag returns the weak ETag of the response to the request, for the data version
+func tag(version string, r *http.Request) string {
+	hash := sha256.New()
+	for _, part := range []string{
+		startedAt,
+		r.URL.RequestURI(),
+		r.Header.Get("Accept"),
+		r.Header.Get("Accept-Language"),
+	} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return `W/"` + version + "-" + hex.EncodeToString(hash.Sum(nil))[:16] + `"`
+}
+
+// match reports whether the If-None-Match header holds the ETag, with the weak comparison of RFC 9110
+func match(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// etagResponseWriter sets the ETag header of successful responses
+type etagResponseWriter struct {
+	http.ResponseWriter
+	etag        string
+	wroteHeader bool
+}
+
+// WriteHeader sets the ETag header if the status is 200 OK, and writes the header
+func (w *etagResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if status == http.StatusOK {
+		w.Header().Set("ETag", w.etag)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write writes the body, writing the header first if need be
+func (w *etagResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying response writer, for http.ResponseController
+func (w *etagResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestETag(t *testing.T) {
+	version := func() (string, error) {
+		return "v1", nil
+	}
+	// etagOf returns the ETag of the response to a GET request for the target, for the data version
+	etagOf := func(version, target string) string {
+		return tag(version, httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	etag := etagOf("v1", "/details?id=1")
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		ifNoneMatch string
+		version     func() (string, error)
+		status      int
+		wantStatus  int
+		wantETag    string
+		wantCalled  bool
+	}{
+		{
+			name: "Tagged", method: http.MethodGet, target: "/details?id=1", version: version,
+			status: http.StatusOK, wantStatus: http.StatusOK, wantETag: etag, wantCalled: true,
+		},
+		{
+			name: "Not modified", method: http.MethodGet, target: "/details?id=1", ifNoneMatch: etag, version: version,
+			status: http.StatusOK, wantStatus: http.StatusNotModified, wantETag: etag,
+		},
+		{
+			name: "Weak comparison", method: http.MethodGet, target: "/details?id=1",
+			ifNoneMatch: `"other", ` + etag[2:], version: version, status: http.StatusOK,
+			wantStatus: http.StatusNotModified, wantETag: etag,
+		},
+		{
+			name: "Any", method: http.MethodGet, target: "/details?id=1", ifNoneMatch: "*", version: version,
+			status: http.StatusOK, wantStatus: http.StatusNotModified, wantETag: etag,
+		},
+		{
+			name: "Other URL", method: http.MethodGet, target: "/details?id=2", ifNoneMatch: etag, version: version,
+			status: http.StatusOK, wantStatus: http.StatusOK, wantETag: etagOf("v1", "/details?id=2"),
+			wantCalled: true,
+		},
+		{
+			name: "Data changed", method: http.MethodGet, target: "/details?id=1", ifNoneMatch: etag,
+			version: func() (string, error) {
+				return "v2", nil
+			},
+			status: http.StatusOK, wantStatus: http.StatusOK, wantETag: etagOf("v2", "/details?id=1"), wantCalled: true,
+		},
+		{
+			name: "Error response", method: http.MethodGet, target: "/details?id=99", version: version,
+			status: http.StatusNotFound, wantStatus: http.StatusNotFound, wantCalled: true,
+		},
+		{
+			name: "Version unavailable", method: http.MethodGet, target: "/details?id=1", ifNoneMatch: etag,
+			version: func() (string, error) {
+				return "", errors.New("unavailable")
+			},
+			status: http.StatusServiceUnavailable, wantStatus: http.StatusServiceUnavailable, wantCalled: true,
+		},
+		{
+			name: "POST", method: http.MethodPost, target: "/details?id=1", ifNoneMatch: etag, version: version,
+			status: http.StatusMethodNotAllowed, wantStatus: http.StatusMethodNotAllowed, wantCalled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				called := false
+				handler := ETag(
+					tt.version, http.HandlerFunc(
+						func(w http.ResponseWriter, r *http.Request) {
+							called = true
+							w.WriteHeader(tt.status)
+							_, _ = io.WriteString(w, "body")
+						},
+					),
+				)
+				r := httptest.NewRequest(tt.method, tt.target, nil)
+				if tt.ifNoneMatch != "" {
+					r.Header.Set("If-None-Match", tt.ifNoneMatch)
+				}
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+
+				if w.Code != tt.wantStatus {
+					t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+				}
+				if got := w.Header().Get("ETag"); got != tt.wantETag {
+					t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+				}
+				if called != tt.wantCalled {
+					t.Errorf("handler called = %t, want %t", called, tt.wantCalled)
+				}
+			},
+		)
+	}
+}
+
+func TestTagVariants(t *testing.T) {
+	english := httptest.NewRequest(http.MethodGet, "/", nil)
+	english.Header.Set("Accept-Language", "en-GB")
+	french := httptest.NewRequest(http.MethodGet, "/", nil)
+	french.Header.Set("Accept-Language", "fr-FR")
+
+	if tag("v1", english) == tag("v1", french) {
+		t.Error("tag() is the same for different Accept-Language headers, want an ETag per variant")
+	}
+	if tag("v1", english) != tag("v1", english.Clone(english.Context())) {
+		t.Error("tag() differs for the same request, want the same ETag")
+	}
+}
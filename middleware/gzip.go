@@ -0,0 +1,125 @@
+// Package middleware holds the HTTP middleware shared by the routes: response compression, and ETags for
+// the responses derived from the cached data
+package middleware
+
+import (
+	"compress/gzip"
+	"groupie-tracker/xhttp"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// minGzipSize is the smallest response worth compressing, when its length is known in advance
+const minGzipSize = 1024
+
+// compressible are the media types of the responses worth compressing, besides text/*
+var compressible = map[string]bool{
+	"application/json":         true,
+	"application/problem+json": true,
+	"application/javascript":   true,
+	"image/svg+xml":            true,
+}
+
+// gzipWriters are reused across responses, as they allocate large buffers
+var gzipWriters = sync.Pool{
+	New: func() any {
+		return gzip.NewWriter(nil)
+	},
+}
+
+// Gzip compresses the responses of next with gzip, for the clients that accept it. The responses that are
+// already encoded, such as the precompressed static files, small, or of a media type that doesn't compress
+// well, such as images, are left as they are.
+func Gzip(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !xhttp.AcceptsEncoding(r.Header.Get("Accept-Encoding"), "gzip") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			gw := &gzipResponseWriter{ResponseWriter: w, head: r.Method == http.MethodHead}
+			defer gw.close()
+			next.ServeHTTP(gw, r)
+		},
+	)
+}
+
+// gzipResponseWriter compresses the response body, if it's worth it, as decided when the header is written
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	head bool
+	// wroteHeader reports whether the header was written, and gz is the gzip writer if the body is compressed
+	wroteHeader bool
+	gz          *gzip.Writer
+}
+
+// WriteHeader decides whether to compress the body, from the response header, and writes it
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	if !w.head && compress(w.Header(), status) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Del("Content-Length")
+		w.gz = gzipWriters.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write writes the body, compressed if so decided by WriteHeader
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz == nil {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.gz.Write(b)
+}
+
+// Unwrap returns the underlying response writer, for http.ResponseController
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close flushes the compressed body, if any
+func (w *gzipResponseWriter) close() {
+	if w.gz == nil {
+		return
+	}
+	_ = w.gz.Close()
+	w.gz.Reset(nil)
+	gzipWriters.Put(w.gz)
+	w.gz = nil
+}
+
+// compress reports whether a response with the header and status is worth compressing
+func compress(header http.Header, status int) bool {
+	switch {
+	case status < http.StatusOK, status == http.StatusNoContent, status == http.StatusPartialContent,
+		status == http.StatusNotModified:
+		return false
+	case header.Get("Content-Encoding") != "":
+		return false
+	}
+	if length, err := strconv.Atoi(header.Get("Content-Length")); err == nil && length < minGzipSize {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "text/") || compressible[mediaType]
+}
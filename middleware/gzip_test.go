@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGzip(t *testing.T) {
+	large := strings.Repeat("Queen played in London. ", 100)
+
+	tests := []struct {
+		name           string
+		method         string
+		acceptEncoding string
+		contentType    string
+		// contentLength is set as the Content-Length header if not 0
+		contentLength int
+		encoding      string
+		status        int
+		body          string
+		wantEncoding  string
+	}{
+		{
+			name: "HTML", acceptEncoding: "gzip", contentType: "text/html; charset=utf-8", status: http.StatusOK,
+			body: large, wantEncoding: "gzip",
+		},
+		{
+			name: "JSON", acceptEncoding: "br, gzip", contentType: "application/json", status: http.StatusOK,
+			body: large, wantEncoding: "gzip",
+		},
+		{
+			name: "Problem details", acceptEncoding: "gzip", contentType: "application/problem+json",
+			status: http.StatusNotFound, body: large, wantEncoding: "gzip",
+		},
+		{
+			name: "Sniffed content type", acceptEncoding: "gzip", status: http.StatusOK, body: large,
+			wantEncoding: "gzip",
+		},
+		{
+			name: "Not accepted", acceptEncoding: "br", contentType: "text/html", status: http.StatusOK, body: large,
+		},
+		{
+			name: "Refused", acceptEncoding: "gzip;q=0", contentType: "text/html", status: http.StatusOK, body: large,
+		},
+		{
+			name: "Image", acceptEncoding: "gzip", contentType: "image/png", status: http.StatusOK, body: large,
+		},
+		{
+			name: "Small", acceptEncoding: "gzip", contentType: "text/css", contentLength: 12, status: http.StatusOK,
+			body: "body { }    ",
+		},
+		{
+			name: "Already encoded", acceptEncoding: "gzip", contentType: "text/css", encoding: "br",
+			status: http.StatusOK, body: large, wantEncoding: "br",
+		},
+		{
+			name: "Not modified", acceptEncoding: "gzip", contentType: "text/html", status: http.StatusNotModified,
+		},
+		{
+			name: "HEAD", method: http.MethodHead, acceptEncoding: "gzip", contentType: "text/html",
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				handler := Gzip(
+					http.HandlerFunc(
+						func(w http.ResponseWriter, r *http.Request) {
+							if tt.contentType != "" {
+								w.Header().Set("Content-Type", tt.contentType)
+							}
+							if tt.contentLength != 0 {
+								w.Header().Set("Content-Length", strconv.Itoa(tt.contentLength))
+							}
+							if tt.encoding != "" {
+								w.Header().Set("Content-Encoding", tt.encoding)
+							}
+							if tt.contentType != "" {
+								w.WriteHeader(tt.status)
+							}
+							_, _ = io.WriteString(w, tt.body)
+						},
+					),
+				)
+				method := tt.method
+				if method == "" {
+					method = http.MethodGet
+				}
+				r := httptest.NewRequest(method, "/", nil)
+				r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+
+				if w.Code != tt.status {
+					t.Errorf("status = %d, want %d", w.Code, tt.status)
+				}
+				if got := w.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+					t.Fatalf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+				}
+				if got := w.Header().Values("Vary"); len(got) != 1 || got[0] != "Accept-Encoding" {
+					t.Errorf("Vary = %q, want Accept-Encoding", got)
+				}
+
+				body := w.Body.String()
+				if tt.wantEncoding == "gzip" {
+					if w.Header().Get("Content-Length") != "" {
+						t.Errorf("Content-Length = %q, want none", w.Header().Get("Content-Length"))
+					}
+					gz, err := gzip.NewReader(w.Body)
+					if err != nil {
+						t.Fatalf("gzip.NewReader() error = %v", err)
+					}
+					decompressed, err := io.ReadAll(gz)
+					if err != nil {
+						t.Fatalf("reading the gzip body: %v", err)
+					}
+					body = string(decompressed)
+				}
+				if body != tt.body {
+					t.Errorf("body = %q, want %q", body, tt.body)
+				}
+			},
+		)
+	}
+}
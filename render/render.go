@@ -0,0 +1,160 @@
+// Package render holds the HTML page templates, parsed once with html/template and shared functions. In
+// development mode, the templates are parsed again whenever their files change.
+package render
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"io"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// Registry holds the templates parsed from the *.html files at the root of a file system, each named after its
+// file name
+type Registry struct {
+	fsys  fs.FS
+	funcs template.FuncMap
+	dev   bool
+
+	mu      sync.RWMutex
+	entries map[string]*entry
+}
+
+// entry is a parsed template file
+type entry struct {
+	// template is never executed, so that it can be cloned
+	template *template.Template
+	// modTime and size identify the version of the file the template was parsed from
+	modTime time.Time
+	size    int64
+	// variants are the clones of the template that are executed, by variant key
+	variants map[string]*template.Template
+}
+
+// New returns a registry of the templates at the root of fsys, e.g. an embed.FS or os.DirFS, each parsed with the
+// given functions on first use, or all at once by Load. In dev mode, the templates are parsed again when their
+// files change, as reported by fs.Stat.
+func New(fsys fs.FS, funcs template.FuncMap, dev bool) *Registry {
+	return &Registry{fsys: fsys, funcs: funcs, dev: dev, entries: make(map[string]*entry)}
+}
+
+// Load parses all the templates, reporting the first template that fails to parse, or an error if there are none,
+// e.g. when the server runs from a directory without templates
+func (r *Registry) Load() error {
+	names, err := fs.Glob(r.fsys, "*.html")
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return errors.New("no *.html templates found")
+	}
+
+	for _, name := range names {
+		if _, err := r.load(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lookup returns the template with the given file name, e.g. `index.html`. The template must not be executed,
+// as it is cloned by Execute and ExecuteWith, which should be used instead.
+func (r *Registry) Lookup(name string) (*template.Template, error) {
+	e, err := r.load(name)
+	if err != nil {
+		return nil, err
+	}
+	return e.template, nil
+}
+
+// Execute renders the named template with the data into w, with the shared functions.
+// The template is rendered into a buffer first, so nothing is written to w if it fails.
+func (r *Registry) Execute(w io.Writer, name string, data any) error {
+	return r.ExecuteWith(w, name, "", nil, data)
+}
+
+// ExecuteWith renders the named template with the data into w, with the given functions replacing the shared
+// functions of the same name, e.g. to format dates in the client's locale. The template is cloned with the
+// functions once per key, which must identify the functions, e.g. the locale they format dates in.
+// The template is rendered into a buffer first, so nothing is written to w if it fails.
+func (r *Registry) ExecuteWith(w io.Writer, name, key string, funcs template.FuncMap, data any) error {
+	temp, err := r.variant(name, key, funcs)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := temp.Execute(&buf, data); err != nil {
+		return err
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// variant returns the clone of the named template with the functions of the key, cloning it on first use
+func (r *Registry) variant(name, key string, funcs template.FuncMap) (*template.Template, error) {
+	e, err := r.load(name)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	clone, ok := e.variants[key]
+	r.mu.RUnlock()
+	if ok {
+		return clone, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if clone, ok := e.variants[key]; ok {
+		return clone, nil
+	}
+	clone, err = e.template.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if len(funcs) > 0 {
+		clone.Funcs(funcs)
+	}
+	e.variants[key] = clone
+	return clone, nil
+}
+
+// load returns the parsed template file, parsing it if it has not been parsed yet, or, in dev mode, if the file
+// changed since it was parsed
+func (r *Registry) load(name string) (*entry, error) {
+	r.mu.RLock()
+	e, ok := r.entries[name]
+	r.mu.RUnlock()
+	if ok && !r.dev {
+		return e, nil
+	}
+
+	info, err := fs.Stat(r.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if ok && info.ModTime().Equal(e.modTime) && info.Size() == e.size {
+		return e, nil
+	}
+
+	temp, err := template.New(name).Funcs(r.funcs).ParseFS(r.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	e = &entry{
+		template: temp,
+		modTime:  info.ModTime(),
+		size:     info.Size(),
+		variants: make(map[string]*template.Template),
+	}
+	r.mu.Lock()
+	r.entries[name] = e
+	r.mu.Unlock()
+	return e, nil
+}
@@ -0,0 +1,142 @@
+package render
+
+import (
+	"bytes"
+	"html/template"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTemplate writes the template file, with a modification time distinct from any previous write
+func writeTemplate(t *testing.T, dir, name, content string, modTime time.Time) {
+	t.Helper()
+	file := filepath.Join(dir, name)
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	funcs := template.FuncMap{
+		"greet": func() string {
+			return "Hello"
+		},
+	}
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		dev  bool
+		// want is the rendering after the template file changed
+		want string
+	}{
+		{name: "Parsed once", dev: false, want: "Hello, &lt;b&gt;!"},
+		{name: "Reloaded in dev mode", dev: true, want: "Hello again, &lt;b&gt;!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				dir := t.TempDir()
+				writeTemplate(t, dir, "page.html", `{{greet}}, {{.}}!`, start)
+				registry := New(os.DirFS(dir), funcs, tt.dev)
+				if err := registry.Load(); err != nil {
+					t.Fatalf("Load() error = %v", err)
+				}
+
+				var buf bytes.Buffer
+				if err := registry.Execute(&buf, "page.html", "<b>"); err != nil {
+					t.Fatalf("Execute() error = %v", err)
+				}
+				if got := buf.String(); got != "Hello, &lt;b&gt;!" {
+					t.Errorf("Execute() = %q, want the data escaped", got)
+				}
+
+				writeTemplate(t, dir, "page.html", `{{greet}} again, {{.}}!`, start.Add(time.Second))
+				buf.Reset()
+				if err := registry.Execute(&buf, "page.html", "<b>"); err != nil {
+					t.Fatalf("Execute() error = %v", err)
+				}
+				if got := buf.String(); got != tt.want {
+					t.Errorf("Execute() after change = %q, want %q", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestRegistryExecuteWith(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "page.html", `{{greet}}`, time.Now())
+	registry := New(
+		os.DirFS(dir), template.FuncMap{
+			"greet": func() string {
+				return "Hello"
+			},
+		}, false,
+	)
+
+	tests := []struct {
+		name  string
+		key   string
+		funcs template.FuncMap
+		want  string
+	}{
+		{name: "Shared functions", key: "", want: "Hello"},
+		{
+			name: "Replaced functions",
+			key:  "fr",
+			funcs: template.FuncMap{
+				"greet": func() string {
+					return "Bonjour"
+				},
+			},
+			want: "Bonjour",
+		},
+		{name: "Shared functions are kept", key: "", want: "Hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var buf bytes.Buffer
+				if err := registry.ExecuteWith(&buf, "page.html", tt.key, tt.funcs, nil); err != nil {
+					t.Fatalf("ExecuteWith() error = %v", err)
+				}
+				if got := buf.String(); got != tt.want {
+					t.Errorf("ExecuteWith() = %q, want %q", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestRegistryErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "broken.html", `{{.Missing}`, time.Now())
+	writeTemplate(t, dir, "failing.html", `before{{.Missing.Field}}`, time.Now())
+	registry := New(os.DirFS(dir), nil, false)
+
+	if err := registry.Load(); err == nil {
+		t.Error("Load() error = nil, want the parse error of broken.html")
+	}
+	if err := New(os.DirFS(t.TempDir()), nil, false).Load(); err == nil {
+		t.Error("Load() error = nil, want an error for a directory without templates")
+	}
+	if _, err := registry.Lookup("missing.html"); err == nil {
+		t.Error("Lookup() error = nil, want an error for a missing template")
+	}
+
+	var buf bytes.Buffer
+	if err := registry.Execute(&buf, "failing.html", struct{ Missing *struct{ Field string } }{}); err == nil {
+		t.Error("Execute() error = nil, want the execution error")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Execute() wrote %q, want nothing written on error", buf.String())
+	}
+}
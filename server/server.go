@@ -0,0 +1,106 @@
+// Package server routes the requests to the handlers, with method and path patterns such as `GET /artists/{artist}`
+package server
+
+import (
+	"groupie-tracker/filter"
+	"groupie-tracker/handlers"
+	"groupie-tracker/httperr"
+	"groupie-tracker/middleware"
+	"net/http"
+	"strings"
+)
+
+// New returns the handler of all the routes of the application, served by the app's handlers, with the responses
+// compressed with gzip. The static files are served by static, under /static/. The responses built from the app's
+// data are tagged with ETags, see middleware.ETag, unless in dev mode, where the templates may change at any time.
+//
+// The requests no route matches are answered with 404 Not Found, or with 405 Method Not Allowed along with the
+// Allow header if the path matches another method, in the format the client accepts: the error page, or
+// problem details for the API.
+func New(app *handlers.App, static http.Handler, dev bool) http.Handler {
+	cached := func(handler http.HandlerFunc) http.Handler {
+		if dev {
+			return handler
+		}
+		return middleware.ETag(app.DataVersion, handler)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /{$}", cached(app.IndexHandler))
+	mux.Handle("GET /artists/{artist}", cached(app.DetailsHandler))
+	mux.Handle("GET /artists/{artist}/concerts.ics", cached(app.ArtistCalendarHandler))
+	mux.Handle("GET /concerts.ics", cached(app.CalendarHandler))
+	mux.Handle("GET /compare", cached(app.CompareHandler))
+	mux.Handle("GET /stats", cached(app.StatsHandler))
+	mux.Handle("GET /filter", cached(app.Filter))
+	mux.Handle("GET /search-suggestions", cached(app.SearchHandler))
+	mux.HandleFunc("POST /api/filter", filter.API(app.Store(), app.Errors()))
+	mux.HandleFunc("POST /api/filter/export", filter.Export(app.Store(), app.Errors()))
+	mux.Handle("GET /api/v1/compare", cached(app.CompareAPIHandler))
+	mux.Handle("GET /api/v1/artists/{id}/{resource}", cached(app.ArtistAPIHandler))
+	mux.Handle("GET /api/v1/stats", cached(app.StatsAPIHandler))
+
+	// the former artist URLs redirect to the current ones
+	mux.HandleFunc("GET /details", app.LegacyDetailsHandler)
+	mux.HandleFunc("GET /details/{artist}/concerts.ics", app.ArtistCalendarHandler)
+
+	// Browsers ping for the /favicon.ico icon, redirect to the respective static file
+	mux.Handle("GET /favicon.ico", http.RedirectHandler("/static/images/favicon.svg", http.StatusMovedPermanently))
+	// Serve the static files at their plain and content-hashed paths, but not the directory entries
+	mux.Handle("GET /static/", static)
+
+	return middleware.Gzip(router{mux: mux, errors: app.Errors()})
+}
+
+// router serves the requests with the mux, responding to the requests no route matches with errors, rather than
+// with the plain text responses of http.ServeMux
+type router struct {
+	mux    *http.ServeMux
+	errors *httperr.Writer
+}
+
+// ServeHTTP serves the request with the handler of the route it matches, if any
+func (rt router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler, pattern := rt.mux.Handler(r)
+	if pattern != "" {
+		rt.mux.ServeHTTP(w, r)
+		return
+	}
+
+	// the mux responds with 404 Not Found, or 405 Method Not Allowed along with the Allow header
+	unmatched := &statusRecorder{header: make(http.Header)}
+	handler.ServeHTTP(unmatched, r)
+	if allow := unmatched.header.Get("Allow"); allow != "" {
+		w.Header().Set("Allow", allow)
+	}
+
+	format := httperr.HTML
+	if strings.HasPrefix(r.URL.Path, "/api/") || r.URL.Path == "/search-suggestions" {
+		format = httperr.JSON
+	}
+	rt.errors.Write(w, r, format, httperr.New(unmatched.status, ""))
+}
+
+// statusRecorder records the header and status of a response, discarding its body
+type statusRecorder struct {
+	header http.Header
+	status int
+}
+
+// Header returns the recorded header
+func (rec *statusRecorder) Header() http.Header {
+	return rec.header
+}
+
+// WriteHeader records the status
+func (rec *statusRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+}
+
+// Write discards the body, recording the status 200 OK if no status was written
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	return len(b), nil
+}
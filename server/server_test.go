@@ -0,0 +1,172 @@
+package server
+
+import (
+	"groupie-tracker/api"
+	"groupie-tracker/domain"
+	"groupie-tracker/handlers"
+	"groupie-tracker/httperr"
+	"groupie-tracker/render"
+	"groupie-tracker/stats"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// templates holds the page templates of the templates directory
+var templates *render.Registry
+
+func TestMain(m *testing.M) {
+	// During tests, the templates dir is in the parent directory
+	var err error
+	templates, err = handlers.NewTemplates(os.DirFS(filepath.Join("..", "templates")), nil, false)
+	if err != nil {
+		log.Fatalf("Error loading templates: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeStore serves the artists of a snapshot
+type fakeStore struct {
+	snapshot *domain.Snapshot
+}
+
+func (s fakeStore) Snapshot() (*domain.Snapshot, error) {
+	return s.snapshot, nil
+}
+
+func (s fakeStore) Stats() (stats.Stats, error) {
+	return stats.New(s.snapshot), nil
+}
+
+func (s fakeStore) Version() (string, error) {
+	return "test", nil
+}
+
+// newFakeStore returns a store of a single artist, Queen, with ID 1
+func newFakeStore(t *testing.T) fakeStore {
+	t.Helper()
+	snapshot, err := domain.NewSnapshot(
+		[]api.Artist{
+			{ID: 1, Name: "Queen", Members: []string{"Freddie Mercury"}, CreationDate: 1970, FirstAlbum: "14-12-1973"},
+		},
+		[]api.Location{{Id: 1, Locations: []string{"london-uk"}}},
+		[]api.Relations{{Id: 1, DatesLocation: map[string][]string{"london-uk": {"31-12-2019"}}}},
+		time.Now(),
+	)
+	if err != nil {
+		t.Fatalf("NewSnapshot() error = %v", err)
+	}
+	return fakeStore{snapshot: snapshot}
+}
+
+func TestNew(t *testing.T) {
+	static := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("static " + r.URL.Path))
+		},
+	)
+	app := handlers.New(newFakeStore(t), templates, handlers.ClockFunc(time.Now), "")
+	handler := New(app, static, true)
+
+	tests := []struct {
+		name            string
+		method          string
+		target          string
+		wantStatus      int
+		wantAllow       string
+		wantContentType string
+		wantLocation    string
+	}{
+		{
+			name: "Page method not allowed", method: "POST", target: "/",
+			wantStatus: http.StatusMethodNotAllowed, wantAllow: "GET, HEAD", wantContentType: "text/html; charset=utf-8",
+		},
+		{
+			name: "Artist page method not allowed", method: "DELETE", target: "/artists/1-queen",
+			wantStatus: http.StatusMethodNotAllowed, wantAllow: "GET, HEAD", wantContentType: "text/html; charset=utf-8",
+		},
+		{
+			name: "API method not allowed", method: "GET", target: "/api/filter",
+			wantStatus: http.StatusMethodNotAllowed, wantAllow: "POST", wantContentType: httperr.ProblemContentType,
+		},
+		{
+			name: "Search method not allowed", method: "POST", target: "/search-suggestions?q=queen",
+			wantStatus: http.StatusMethodNotAllowed, wantAllow: "GET, HEAD", wantContentType: httperr.ProblemContentType,
+		},
+		{
+			name: "Page not found", method: "GET", target: "/invalid",
+			wantStatus: http.StatusNotFound, wantContentType: "text/html; charset=utf-8",
+		},
+		{
+			name: "Artist resource missing", method: "GET", target: "/api/v1/artists/1",
+			wantStatus: http.StatusNotFound, wantContentType: httperr.ProblemContentType,
+		},
+		{
+			name: "Artist page", method: "GET", target: "/artists/1-queen",
+			wantStatus: http.StatusOK, wantContentType: "text/html; charset=utf-8",
+		},
+		{
+			name: "Artist page without slug", method: "GET", target: "/artists/1",
+			wantStatus: http.StatusMovedPermanently, wantLocation: "/artists/1-queen",
+		},
+		{
+			name: "Artist page not found", method: "GET", target: "/artists/99-queen",
+			wantStatus: http.StatusNotFound, wantContentType: "text/html; charset=utf-8",
+		},
+		{
+			name: "Legacy artist page", method: "GET", target: "/details?id=1",
+			wantStatus: http.StatusMovedPermanently, wantLocation: "/artists/1-queen",
+		},
+		{
+			name: "Legacy artist calendar", method: "GET", target: "/details/1/concerts.ics",
+			wantStatus: http.StatusMovedPermanently, wantLocation: "/artists/1-queen/concerts.ics",
+		},
+		{
+			name: "Artist calendar", method: "GET", target: "/artists/1-queen/concerts.ics",
+			wantStatus: http.StatusOK, wantContentType: "text/calendar; charset=utf-8",
+		},
+		{
+			name: "Artist resource", method: "GET", target: "/api/v1/artists/1/tour",
+			wantStatus: http.StatusOK, wantContentType: "application/json",
+		},
+		{
+			name: "Favicon", method: "GET", target: "/favicon.ico",
+			wantStatus: http.StatusMovedPermanently, wantLocation: "/static/images/favicon.svg",
+		},
+		{
+			name: "Static file", method: "GET", target: "/static/css/index.css",
+			wantStatus: http.StatusOK, wantContentType: "text/plain; charset=utf-8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(tt.method, tt.target, nil)
+				rr := httptest.NewRecorder()
+
+				handler.ServeHTTP(rr, req)
+
+				if rr.Code != tt.wantStatus {
+					t.Errorf("status = %v, want %v", rr.Code, tt.wantStatus)
+				}
+				if got := rr.Header().Get("Allow"); got != tt.wantAllow {
+					t.Errorf("Allow = %q, want %q", got, tt.wantAllow)
+				}
+				if got := rr.Header().Get("Content-Type"); tt.wantContentType != "" && got != tt.wantContentType {
+					t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+				}
+				if got := rr.Header().Get("Location"); got != tt.wantLocation {
+					t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+				}
+				if tt.wantStatus >= http.StatusBadRequest && rr.Body.Len() == 0 {
+					t.Error("Expected error content, got empty response")
+				}
+			},
+		)
+	}
+}
@@ -1,3 +1,20 @@
+// suggestionSource returns a human-readable description of where a structured
+// search suggestion was matched from, e.g. `member (Queen)`
+function suggestionSource(suggestionObject) {
+    switch (suggestionObject.kind) {
+        case "artist":
+            return "artist/band";
+        case "member":
+            return `member (${suggestionObject.artist_name})`;
+        case "first_album":
+            return `first album date (${suggestionObject.artist_name})`;
+        case "creation_date":
+            return `creation date (${suggestionObject.artist_name})`;
+        default:
+            return "location";
+    }
+}
+
 // search ranking:
 //
 // 1. Elements that match the whole term: score +100
@@ -7,7 +24,7 @@
 function SearchScore(allSuggestions, query, minScore = 2) {
     const searchScores = [];
     allSuggestions.forEach((suggestionObject) => {
-        const suggestion = String(suggestionObject.suggestion).toLowerCase();
+        const suggestion = String(suggestionObject.value).toLowerCase();
         query = String(query).toLowerCase();
 
         let score = 0;
@@ -28,7 +45,7 @@ function SearchScore(allSuggestions, query, minScore = 2) {
         }
 
         if (score >= minScore) {
-            searchScores.push({suggestion: `${suggestion} - ${suggestionObject.from}`, score: score});
+            searchScores.push({suggestion: `${suggestion} - ${suggestionSource(suggestionObject)}`, score: score});
         }
     });
 
@@ -46,8 +63,8 @@ document.addEventListener("DOMContentLoaded", () => {
     // Fetch and cache suggestions on page load
     fetch('/search-suggestions?init=true')
         .then(response => response.json())
-        .then(suggestions => {
-            cachedSuggestions = suggestions;
+        .then(payload => {
+            cachedSuggestions = payload.suggestions;
         })
         .catch(err => {
             console.error("Error fetching initial suggestions:", err);
@@ -0,0 +1,180 @@
+// Package stats computes aggregate statistics over the Groupie Trackers data
+package stats
+
+import (
+	"fmt"
+	"groupie-tracker/domain"
+	"sort"
+	"strconv"
+)
+
+// topCount is the number of entries kept in the busiest and most-travelled rankings
+const topCount = 10
+
+// Count is a labelled number, such as the number of artists formed in a decade
+type Count struct {
+	Label string `json:"label"`
+	Value int    `json:"value"`
+}
+
+// Traveller is an artist ranked by how widely it toured
+type Traveller struct {
+	ArtistID  int    `json:"artistId"`
+	Name      string `json:"name"`
+	Countries int    `json:"countries"`
+	Cities    int    `json:"cities"`
+	Concerts  int    `json:"concerts"`
+}
+
+// Career holds the creation and first album years of an artist
+type Career struct {
+	ArtistID     int    `json:"artistId"`
+	Name         string `json:"name"`
+	CreationDate int    `json:"creationDate"`
+	// FirstAlbumYear is 0 if the first album date is unknown
+	FirstAlbumYear int `json:"firstAlbumYear"`
+}
+
+// Stats are aggregate statistics over all artists
+type Stats struct {
+	ArtistCount  int `json:"artistCount"`
+	ConcertCount int `json:"concertCount"`
+	// FormationDecades counts the artists formed in each decade, e.g. `1970s`, oldest first
+	FormationDecades []Count `json:"formationDecades"`
+	// MemberCounts counts the artists by number of members, fewest first
+	MemberCounts []Count `json:"memberCounts"`
+	// YearsToFirstAlbum counts the artists by number of years between their creation and first album,
+	// leaving out the artists whose first album date is unknown
+	YearsToFirstAlbum []Count `json:"yearsToFirstAlbum"`
+	// AverageYearsToFirstAlbum is the mean of YearsToFirstAlbum
+	AverageYearsToFirstAlbum float64 `json:"averageYearsToFirstAlbum"`
+	// BusiestCities are the cities that held the most concerts, most first
+	BusiestCities []Count `json:"busiestCities"`
+	// BusiestCountries are the countries that held the most concerts, most first
+	BusiestCountries []Count `json:"busiestCountries"`
+	// ConcertsPerYear counts the concerts held each year, oldest first
+	ConcertsPerYear []Count `json:"concertsPerYear"`
+	// MostTravelled are the artists who played in the most countries, then cities, most first
+	MostTravelled []Traveller `json:"mostTravelled"`
+	// Careers are the creation and first album years of every artist, ordered by artist ID
+	Careers []Career `json:"careers"`
+}
+
+// New computes the statistics of the snapshot's artists
+func New(snapshot *domain.Snapshot) Stats {
+	var s Stats
+	if snapshot == nil {
+		return s
+	}
+
+	decades := make(map[int]int)
+	members := make(map[int]int)
+	yearsToAlbum := make(map[int]int)
+	cities := make(map[string]int)
+	countries := make(map[string]int)
+	years := make(map[int]int)
+	totalYearsToAlbum, albumCount := 0, 0
+
+	for _, artist := range snapshot.Artists {
+		s.ArtistCount++
+		s.ConcertCount += len(artist.Concerts)
+		decades[artist.CreationDate/10*10]++
+		members[len(artist.Members)]++
+
+		career := Career{ArtistID: artist.ID, Name: artist.Name, CreationDate: artist.CreationDate}
+		if !artist.FirstAlbum.IsZero() {
+			career.FirstAlbumYear = artist.FirstAlbum.Year()
+			n := career.FirstAlbumYear - artist.CreationDate
+			yearsToAlbum[n]++
+			totalYearsToAlbum += n
+			albumCount++
+		}
+		s.Careers = append(s.Careers, career)
+
+		traveller := Traveller{ArtistID: artist.ID, Name: artist.Name, Concerts: len(artist.Concerts)}
+		artistCities := make(map[string]bool)
+		artistCountries := make(map[string]bool)
+		for _, concert := range artist.Concerts {
+			years[concert.Date.Year()]++
+			// the places of the slugs that name a region or a country, e.g. `texas-usa`, have no city, and
+			// those of the slugs missing from the location dictionary may have no country
+			if place := concert.Place; place.City != "" {
+				cities[place.String()]++
+				artistCities[place.String()] = true
+			}
+			if country := concert.Place.Country; country != "" {
+				countries[country]++
+				artistCountries[country] = true
+			}
+		}
+		traveller.Cities, traveller.Countries = len(artistCities), len(artistCountries)
+		s.MostTravelled = append(s.MostTravelled, traveller)
+	}
+
+	s.FormationDecades = ordered(
+		decades, func(decade int) string {
+			return fmt.Sprintf("%ds", decade)
+		},
+	)
+	s.MemberCounts = ordered(members, strconv.Itoa)
+	s.YearsToFirstAlbum = ordered(yearsToAlbum, strconv.Itoa)
+	if albumCount > 0 {
+		s.AverageYearsToFirstAlbum = float64(totalYearsToAlbum) / float64(albumCount)
+	}
+	s.BusiestCities = top(cities)
+	s.BusiestCountries = top(countries)
+	s.ConcertsPerYear = ordered(years, strconv.Itoa)
+
+	sort.SliceStable(
+		s.MostTravelled, func(i, j int) bool {
+			a, b := s.MostTravelled[i], s.MostTravelled[j]
+			if a.Countries != b.Countries {
+				return a.Countries > b.Countries
+			}
+			if a.Cities != b.Cities {
+				return a.Cities > b.Cities
+			}
+			return a.Name < b.Name
+		},
+	)
+	if len(s.MostTravelled) > topCount {
+		s.MostTravelled = s.MostTravelled[:topCount]
+	}
+	return s
+}
+
+// ordered returns the counts ordered by key, smallest first, labelled with the given function
+func ordered(counts map[int]int, label func(int) string) []Count {
+	keys := make([]int, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
+	result := make([]Count, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, Count{Label: label(key), Value: counts[key]})
+	}
+	return result
+}
+
+// top returns the topCount largest counts, largest first, then in alphabetical order of the labels
+func top(counts map[string]int) []Count {
+	result := make([]Count, 0, len(counts))
+	for label, value := range counts {
+		result = append(result, Count{Label: label, Value: value})
+	}
+	sort.Slice(
+		result, func(i, j int) bool {
+			if result[i].Value != result[j].Value {
+				return result[i].Value > result[j].Value
+			}
+			return result[i].Label < result[j].Label
+		},
+	)
+
+	if len(result) > topCount {
+		result = result[:topCount]
+	}
+	return result
+}
@@ -0,0 +1,148 @@
+package stats
+
+import (
+	"groupie-tracker/api"
+	"groupie-tracker/domain"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	artists := []api.Artist{
+		{ID: 1, Name: "Queen", Members: []string{"a", "b", "c", "d"}, CreationDate: 1970, FirstAlbum: "14-12-1973"},
+		{ID: 2, Name: "Pink Floyd", Members: []string{"a", "b", "c", "d"}, CreationDate: 1965, FirstAlbum: "05-08-1967"},
+		{ID: 3, Name: "Coldplay", Members: []string{"a"}, CreationDate: 1996, FirstAlbum: "10-07-2000"},
+	}
+	relations := []api.Relations{
+		{
+			DatesLocation: map[string][]string{
+				"london-uk":  {"01-01-2019", "02-01-2019"},
+				"dallas-usa": {"01-01-2020"},
+			},
+		},
+		{DatesLocation: map[string][]string{"london-uk": {"01-01-2019"}}},
+		{
+			DatesLocation: map[string][]string{
+				"paris-france": {"01-01-2020"},
+				"dallas-usa":   {"01-01-2020"},
+				"london-uk":    {"01-01-2020"},
+			},
+		},
+	}
+	snapshot, err := domain.NewSnapshot(artists, nil, relations, time.Now())
+	if err != nil {
+		t.Fatalf("NewSnapshot() error = %v", err)
+	}
+
+	s := New(snapshot)
+
+	if s.ArtistCount != 3 || s.ConcertCount != 7 {
+		t.Errorf("ArtistCount, ConcertCount = %d, %d, want 3, 7", s.ArtistCount, s.ConcertCount)
+	}
+
+	tests := []struct {
+		name string
+		got  []Count
+		want []Count
+	}{
+		{
+			name: "FormationDecades",
+			got:  s.FormationDecades,
+			want: []Count{{"1960s", 1}, {"1970s", 1}, {"1990s", 1}},
+		},
+		{
+			name: "MemberCounts",
+			got:  s.MemberCounts,
+			want: []Count{{"1", 1}, {"4", 2}},
+		},
+		{
+			name: "YearsToFirstAlbum",
+			got:  s.YearsToFirstAlbum,
+			want: []Count{{"2", 1}, {"3", 1}, {"4", 1}},
+		},
+		{
+			name: "BusiestCities",
+			got:  s.BusiestCities,
+			want: []Count{
+				{"London, England, United Kingdom", 4},
+				{"Dallas, Texas, United States", 2},
+				{"Paris, Île-de-France, France", 1},
+			},
+		},
+		{
+			name: "BusiestCountries",
+			got:  s.BusiestCountries,
+			want: []Count{{"United Kingdom", 4}, {"United States", 2}, {"France", 1}},
+		},
+		{
+			name: "ConcertsPerYear",
+			got:  s.ConcertsPerYear,
+			want: []Count{{"2019", 3}, {"2020", 4}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if !reflect.DeepEqual(tt.got, tt.want) {
+					t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+				}
+			},
+		)
+	}
+
+	if s.AverageYearsToFirstAlbum != 3 {
+		t.Errorf("AverageYearsToFirstAlbum = %v, want 3", s.AverageYearsToFirstAlbum)
+	}
+
+	wantCareer := Career{ArtistID: 3, Name: "Coldplay", CreationDate: 1996, FirstAlbumYear: 2000}
+	if len(s.Careers) != 3 || s.Careers[2] != wantCareer {
+		t.Errorf("Careers = %+v, want the last to be %+v", s.Careers, wantCareer)
+	}
+
+	var travelled []string
+	for _, traveller := range s.MostTravelled {
+		travelled = append(travelled, traveller.Name)
+	}
+	if want := []string{"Coldplay", "Queen", "Pink Floyd"}; !reflect.DeepEqual(travelled, want) {
+		t.Errorf("MostTravelled = %v, want %v", travelled, want)
+	}
+}
+
+func TestNewPlacesWithoutCityOrCountry(t *testing.T) {
+	artists := []api.Artist{{ID: 1, Name: "Queen", FirstAlbum: "14-12-1973"}}
+	relations := []api.Relations{
+		{
+			// a region, a city missing from the location dictionary without a country, and a city
+			DatesLocation: map[string][]string{
+				"texas-usa": {"01-01-2019"},
+				"atlantis":  {"02-01-2019"},
+				"london-uk": {"03-01-2019"},
+			},
+		},
+	}
+	snapshot, err := domain.NewSnapshot(artists, nil, relations, time.Now())
+	if err != nil {
+		t.Fatalf("NewSnapshot() error = %v", err)
+	}
+
+	s := New(snapshot)
+
+	wantCities := []Count{{"London, England, United Kingdom", 1}, {"atlantis", 1}}
+	if !reflect.DeepEqual(s.BusiestCities, wantCities) {
+		t.Errorf("BusiestCities = %v, want %v", s.BusiestCities, wantCities)
+	}
+	if want := []Count{{"United Kingdom", 1}, {"United States", 1}}; !reflect.DeepEqual(s.BusiestCountries, want) {
+		t.Errorf("BusiestCountries = %v, want %v", s.BusiestCountries, want)
+	}
+	if traveller := s.MostTravelled[0]; traveller.Cities != 2 || traveller.Countries != 2 || traveller.Concerts != 3 {
+		t.Errorf("MostTravelled[0] = %+v, want 2 cities and 2 countries of 3 concerts", traveller)
+	}
+}
+
+func TestNewNilSnapshot(t *testing.T) {
+	if s := New(nil); s.ArtistCount != 0 || s.FormationDecades != nil {
+		t.Errorf("New(nil) = %+v, want zero stats", s)
+	}
+}
@@ -3,3 +3,16 @@ package xerrors
 import "errors"
 
 var ErrNotFound = errors.New("not found")
+
+// ErrBadRequest is wrapped by the errors of invalid client requests
+var ErrBadRequest = errors.New("bad request")
+
+// ErrTooLarge is wrapped by the errors of client requests whose body is larger than accepted
+var ErrTooLarge = errors.New("request too large")
+
+// ErrMethodNotAllowed is wrapped by the errors of requests made with an unsupported HTTP method
+var ErrMethodNotAllowed = errors.New("method not allowed")
+
+// ErrUnavailable is wrapped by the errors of data that is temporarily unavailable, such as when the
+// Groupie Trackers API can't be reached
+var ErrUnavailable = errors.New("temporarily unavailable")
@@ -0,0 +1,34 @@
+package xhttp
+
+import (
+	"strconv"
+	"strings"
+)
+
+// AcceptsEncoding reports whether the Accept-Encoding header accepts the content coding, e.g. `gzip`,
+// explicitly or with `*`, and without a zero quality value
+func AcceptsEncoding(acceptEncoding, coding string) bool {
+	accepted := false
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != coding && name != "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		// an explicit coding takes precedence over `*`
+		if name == coding {
+			return q > 0
+		}
+		accepted = q > 0
+	}
+	return accepted
+}
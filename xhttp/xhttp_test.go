@@ -0,0 +1,26 @@
+package xhttp
+
+import "testing"
+
+func TestAcceptsEncoding(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		coding         string
+		want           bool
+	}{
+		{acceptEncoding: "", coding: "gzip", want: false},
+		{acceptEncoding: "gzip", coding: "gzip", want: true},
+		{acceptEncoding: "deflate, GZIP;q=0.5", coding: "gzip", want: true},
+		{acceptEncoding: "gzip;q=0", coding: "gzip", want: false},
+		{acceptEncoding: "*", coding: "gzip", want: true},
+		{acceptEncoding: "*;q=0, br", coding: "gzip", want: false},
+		{acceptEncoding: "gzip;q=0, *", coding: "gzip", want: false},
+		{acceptEncoding: "identity", coding: "gzip", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := AcceptsEncoding(tt.acceptEncoding, tt.coding); got != tt.want {
+			t.Errorf("AcceptsEncoding(%q, %q) = %t, want %t", tt.acceptEncoding, tt.coding, got, tt.want)
+		}
+	}
+}
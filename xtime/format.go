@@ -0,0 +1,151 @@
+package xtime
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLocale is the locale dates are formatted in when no supported locale is requested
+const DefaultLocale = "en-GB"
+
+// ISOLocale formats dates in the ISO 8601 format, e.g. `1990-01-31`
+const ISOLocale = "iso"
+
+// localeFormat describes how dates are written in a locale
+type localeFormat struct {
+	months [12]string
+	// day, month and year format the respective precisions, replacing {d}, {m} and {y}
+	// with the day of the month, the month name and the year
+	day   string
+	month string
+}
+
+var englishMonths = [12]string{
+	"January", "February", "March", "April", "May", "June",
+	"July", "August", "September", "October", "November", "December",
+}
+
+// locales holds the supported locales, keyed by their lower case BCP 47 language tags
+var locales = map[string]localeFormat{
+	"en-us": {months: englishMonths, day: "{m} {d}, {y}", month: "{m} {y}"},
+	"en-gb": {months: englishMonths, day: "{d} {m} {y}", month: "{m} {y}"},
+	"fr-fr": {
+		months: [12]string{
+			"janvier", "février", "mars", "avril", "mai", "juin",
+			"juillet", "août", "septembre", "octobre", "novembre", "décembre",
+		},
+		day: "{d} {m} {y}", month: "{m} {y}",
+	},
+	"de-de": {
+		months: [12]string{
+			"Januar", "Februar", "März", "April", "Mai", "Juni",
+			"Juli", "August", "September", "Oktober", "November", "Dezember",
+		},
+		day: "{d}. {m} {y}", month: "{m} {y}",
+	},
+	"es-es": {
+		months: [12]string{
+			"enero", "febrero", "marzo", "abril", "mayo", "junio",
+			"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre",
+		},
+		day: "{d} de {m} de {y}", month: "{m} de {y}",
+	},
+}
+
+// languageDefaults maps the languages to the locale used when only the language is requested
+var languageDefaults = map[string]string{
+	"en": "en-gb",
+	"fr": "fr-fr",
+	"de": "de-de",
+	"es": "es-es",
+}
+
+// Locale returns the supported locale that best matches the given Accept-Language header value,
+// e.g. `fr-CA,fr;q=0.9,en;q=0.8` returns `fr-fr`. Returns DefaultLocale if none of the languages is supported.
+func Locale(acceptLanguage string) string {
+	best, bestQuality := "", 0.0
+	for _, entry := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(entry), ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+
+		locale, ok := supportedLocale(tag)
+		if ok && quality > bestQuality {
+			best, bestQuality = locale, quality
+		}
+	}
+
+	if best == "" {
+		return strings.ToLower(DefaultLocale)
+	}
+	return best
+}
+
+// supportedLocale returns the supported locale for the language tag, falling back to the tag's language
+func supportedLocale(tag string) (string, bool) {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if tag == ISOLocale {
+		return tag, true
+	}
+	if _, ok := locales[tag]; ok {
+		return tag, true
+	}
+	language, _, _ := strings.Cut(tag, "-")
+	locale, ok := languageDefaults[language]
+	return locale, ok
+}
+
+// Format returns the date written for the given locale, e.g. `31 January 1990` for `en-GB`
+// or `January 31, 1990` for `en-US`, to the date's precision. Unsupported locales fall back to DefaultLocale.
+func (d Date) Format(locale string) string {
+	locale, ok := supportedLocale(locale)
+	if !ok {
+		locale, _ = supportedLocale(DefaultLocale)
+	}
+
+	if locale == ISOLocale {
+		switch d.Precision {
+		case PrecisionYear:
+			return d.Time.Format("2006")
+		case PrecisionMonth:
+			return d.Time.Format("2006-01")
+		default:
+			return d.Time.Format("2006-01-02")
+		}
+	}
+
+	format := locales[locale]
+	layout := format.day
+	switch d.Precision {
+	case PrecisionYear:
+		return strconv.Itoa(d.Year())
+	case PrecisionMonth:
+		layout = format.month
+	}
+
+	return strings.NewReplacer(
+		"{d}", strconv.Itoa(d.Day()),
+		"{m}", format.months[d.Month()-1],
+		"{y}", strconv.Itoa(d.Year()),
+	).Replace(layout)
+}
+
+// Format returns the day of t written for the given locale. See Date.Format
+func Format(t time.Time, locale string) string {
+	return Date{Time: t, Precision: PrecisionDay}.Format(locale)
+}
+
+// FormatString parses the date in s with ParseDate, and returns it written for the given locale.
+// If s can't be parsed, it is returned unchanged. This is handy in templates, where dates are still strings.
+func FormatString(s, locale string) string {
+	date, err := ParseDate(s)
+	if err != nil {
+		return s
+	}
+	return date.Format(locale)
+}
@@ -7,28 +7,124 @@ import (
 	"time"
 )
 
-// Parse returns the time represented in the given string. The date in the string,
-// is expected to follow the time format DD-MM-YYYY. Returns an error if parsing the time in the format fails
-func Parse(s string) (time.Time, error) {
+// Precision is how precisely a parsed date is known
+type Precision int
+
+const (
+	// PrecisionYear dates only know their year, e.g. `1990`
+	PrecisionYear Precision = iota + 1
+	// PrecisionMonth dates know their year and month, e.g. `1990-01`
+	PrecisionMonth
+	// PrecisionDay dates know their year, month and day, e.g. `1990-01-01`
+	PrecisionDay
+)
+
+// Date is a calendar date, known to the given precision.
+// The embedded time is the first day of the period the date covers, at midnight UTC.
+type Date struct {
+	time.Time
+	Precision Precision
+}
+
+// Start returns the first day of the period covered by the date
+func (d Date) Start() time.Time {
+	return d.Time
+}
+
+// End returns the last day of the period covered by the date, e.g. the 31st of December for a year-only date
+func (d Date) End() time.Time {
+	switch d.Precision {
+	case PrecisionYear:
+		return d.Time.AddDate(1, 0, -1)
+	case PrecisionMonth:
+		return d.Time.AddDate(0, 1, -1)
+	default:
+		return d.Time
+	}
+}
+
+// Contains reports whether the day of t falls within the period covered by the date
+func (d Date) Contains(t time.Time) bool {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return !day.Before(d.Start()) && !day.After(d.End())
+}
+
+// ParseDate returns the date represented in the given string, which may be in any of the forms:
+//
+//   - ISO 8601: `YYYY-MM-DD`, `YYYY-MM`, `YYYY`, or a full timestamp such as `1990-01-01T10:00:00Z`
+//   - day first: `DD-MM-YYYY`, as used by the Groupie Trackers API, optionally prefixed with `*`, and `MM-YYYY`
+//
+// Returns an error if the string is in none of these forms, or if the day or month is out of range,
+// e.g. `32-13-2000`.
+func ParseDate(s string) (Date, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "*")
+
+	if strings.Contains(s, "T") {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return Date{}, fmt.Errorf("xtime: invalid timestamp `%s`: %w", s, err)
+		}
+		return newDate(t.Year(), int(t.Month()), t.Day(), PrecisionDay, s)
+	}
+
 	parts := strings.Split(s, "-")
-	if len(parts) != 3 {
-		return time.Time{}, fmt.Errorf("xtime: invalid format `%s`: expected format: DD-MM-YYYY", s)
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || part == "" || part[0] == '+' {
+			return Date{}, fmt.Errorf("xtime: invalid format `%s`: %s", s, expectedFormats)
+		}
+		numbers[i] = n
 	}
 
-	dd, err := strconv.ParseInt(parts[0], 10, 64)
-	if err != nil {
-		return time.Time{}, err
+	// tell the year apart from the day and month by its four digits
+	isYear := func(i int) bool {
+		return len(parts[i]) == 4
 	}
 
-	mm, err := strconv.ParseInt(parts[1], 10, 64)
-	if err != nil {
-		return time.Time{}, err
+	switch {
+	case len(parts) == 3 && isYear(0) && !isYear(2):
+		return newDate(numbers[0], numbers[1], numbers[2], PrecisionDay, s)
+	case len(parts) == 3 && isYear(2) && !isYear(0):
+		return newDate(numbers[2], numbers[1], numbers[0], PrecisionDay, s)
+	case len(parts) == 2 && isYear(0) && !isYear(1):
+		return newDate(numbers[0], numbers[1], 1, PrecisionMonth, s)
+	case len(parts) == 2 && isYear(1) && !isYear(0):
+		return newDate(numbers[1], numbers[0], 1, PrecisionMonth, s)
+	case len(parts) == 1 && isYear(0):
+		return newDate(numbers[0], 1, 1, PrecisionYear, s)
+	}
+
+	return Date{}, fmt.Errorf("xtime: invalid format `%s`: %s", s, expectedFormats)
+}
+
+const expectedFormats = "expected format: YYYY-MM-DD, DD-MM-YYYY, YYYY-MM, MM-YYYY or YYYY"
+
+// newDate returns the date with the given year, month and day, after validating their ranges
+func newDate(year, month, day int, precision Precision, s string) (Date, error) {
+	if year < 1 || year > 9999 {
+		return Date{}, fmt.Errorf("xtime: year out of range in `%s`: %d", s, year)
+	}
+	if month < 1 || month > 12 {
+		return Date{}, fmt.Errorf("xtime: month out of range in `%s`: %d", s, month)
+	}
+
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	// the time package normalizes out of range days into the following month
+	if day < 1 || t.Month() != time.Month(month) {
+		return Date{}, fmt.Errorf("xtime: day out of range in `%s`: %d", s, day)
 	}
 
-	yy, err := strconv.ParseInt(parts[2], 10, 64)
+	return Date{Time: t, Precision: precision}, nil
+}
+
+// Parse returns the time represented in the given string. The string may be in any of the forms accepted by
+// ParseDate; dates without a day or month resolve to the first day of their period.
+// Returns an error if parsing the time fails.
+func Parse(s string) (time.Time, error) {
+	date, err := ParseDate(s)
 	if err != nil {
 		return time.Time{}, err
 	}
-
-	return time.Date(int(yy), time.Month(mm), int(dd), 0, 0, 0, 0, time.UTC), nil
+	return date.Time, nil
 }
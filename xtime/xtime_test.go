@@ -26,7 +26,49 @@ func TestParse(t *testing.T) {
 		{
 			name:    "32-05-1967",
 			args:    args{s: "32-05-1967"},
-			want:    time.Date(1967, 5, 32, 0, 0, 0, 0, time.UTC),
+			want:    time.Time{},
+			wantErr: true,
+		},
+
+		{
+			name:    "32-13-2000",
+			args:    args{s: "32-13-2000"},
+			want:    time.Time{},
+			wantErr: true,
+		},
+
+		{
+			name:    "29-02-2001",
+			args:    args{s: "29-02-2001"},
+			want:    time.Time{},
+			wantErr: true,
+		},
+
+		{
+			name:    "29-02-2000",
+			args:    args{s: "29-02-2000"},
+			want:    time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC),
+			wantErr: false,
+		},
+
+		{
+			name:    "*27-05-1967",
+			args:    args{s: "*27-05-1967"},
+			want:    time.Date(1967, 5, 27, 0, 0, 0, 0, time.UTC),
+			wantErr: false,
+		},
+
+		{
+			name:    "1990-01-31",
+			args:    args{s: "1990-01-31"},
+			want:    time.Date(1990, 1, 31, 0, 0, 0, 0, time.UTC),
+			wantErr: false,
+		},
+
+		{
+			name:    "1990-01-31T22:00:00Z",
+			args:    args{s: "1990-01-31T22:00:00Z"},
+			want:    time.Date(1990, 1, 31, 0, 0, 0, 0, time.UTC),
 			wantErr: false,
 		},
 
@@ -73,3 +115,126 @@ func TestParse(t *testing.T) {
 		)
 	}
 }
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		s             string
+		wantStart     time.Time
+		wantEnd       time.Time
+		wantPrecision Precision
+		wantErr       bool
+	}{
+		{
+			s:             "1990",
+			wantStart:     time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:       time.Date(1990, 12, 31, 0, 0, 0, 0, time.UTC),
+			wantPrecision: PrecisionYear,
+		},
+		{
+			s:             "1992-02",
+			wantStart:     time.Date(1992, 2, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:       time.Date(1992, 2, 29, 0, 0, 0, 0, time.UTC),
+			wantPrecision: PrecisionMonth,
+		},
+		{
+			s:             "02-1992",
+			wantStart:     time.Date(1992, 2, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:       time.Date(1992, 2, 29, 0, 0, 0, 0, time.UTC),
+			wantPrecision: PrecisionMonth,
+		},
+		{
+			s:             " *05-12-2019 ",
+			wantStart:     time.Date(2019, 12, 5, 0, 0, 0, 0, time.UTC),
+			wantEnd:       time.Date(2019, 12, 5, 0, 0, 0, 0, time.UTC),
+			wantPrecision: PrecisionDay,
+		},
+		{s: "1992-13", wantErr: true},
+		{s: "90", wantErr: true},
+		{s: "1990-01-01-01", wantErr: true},
+		{s: "1990/01/01", wantErr: true},
+		{s: "0000", wantErr: true},
+		{s: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.s, func(t *testing.T) {
+				got, err := ParseDate(tt.s)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("ParseDate() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if tt.wantErr {
+					return
+				}
+				if got.Precision != tt.wantPrecision {
+					t.Errorf("ParseDate() precision = %v, want %v", got.Precision, tt.wantPrecision)
+				}
+				if !got.Start().Equal(tt.wantStart) || !got.End().Equal(tt.wantEnd) {
+					t.Errorf(
+						"ParseDate() covers [%v, %v], want [%v, %v]", got.Start(), got.End(), tt.wantStart, tt.wantEnd,
+					)
+				}
+				if !got.Contains(tt.wantEnd.Add(23*time.Hour)) || got.Contains(tt.wantEnd.AddDate(0, 0, 1)) {
+					t.Errorf("ParseDate() Contains() disagrees with End() = %v", got.End())
+				}
+			},
+		)
+	}
+}
+
+func TestDateFormat(t *testing.T) {
+	day, _ := ParseDate("05-08-1973")
+	month, _ := ParseDate("1973-08")
+	year, _ := ParseDate("1973")
+
+	tests := []struct {
+		locale string
+		date   Date
+		want   string
+	}{
+		{locale: "en-US", date: day, want: "August 5, 1973"},
+		{locale: "en-GB", date: day, want: "5 August 1973"},
+		{locale: "fr-FR", date: day, want: "5 août 1973"},
+		{locale: "de", date: day, want: "5. August 1973"},
+		{locale: "es-ES", date: day, want: "5 de agosto de 1973"},
+		{locale: "iso", date: day, want: "1973-08-05"},
+		{locale: "xx-YY", date: day, want: "5 August 1973"},
+		{locale: "en-US", date: month, want: "August 1973"},
+		{locale: "es-ES", date: month, want: "agosto de 1973"},
+		{locale: "iso", date: month, want: "1973-08"},
+		{locale: "fr-FR", date: year, want: "1973"},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.locale+" "+tt.want, func(t *testing.T) {
+				if got := tt.date.Format(tt.locale); got != tt.want {
+					t.Errorf("Format() = %q, want %q", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestLocale(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{acceptLanguage: "", want: "en-gb"},
+		{acceptLanguage: "en-US,en;q=0.9", want: "en-us"},
+		{acceptLanguage: "fr-CA,fr;q=0.9,en;q=0.8", want: "fr-fr"},
+		{acceptLanguage: "ja,de;q=0.5,es;q=0.7", want: "es-es"},
+		{acceptLanguage: "ja", want: "en-gb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.acceptLanguage, func(t *testing.T) {
+				if got := Locale(tt.acceptLanguage); got != tt.want {
+					t.Errorf("Locale() = %q, want %q", got, tt.want)
+				}
+			},
+		)
+	}
+}